@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/civiledcode/javascribe/dfa"
+	"github.com/t14raptor/go-fast/parser"
+)
+
+var cfgTestsRan = []string{"01", "02", "03", "04", "05"}
+
+// dumpCFG describes every graph of a control flow graph, in the order the functions are created.
+func dumpCFG(c *dfa.CFG) string {
+	var b strings.Builder
+	for i, g := range c.Functions {
+		fmt.Fprintf(&b, "graph %d %T\n%s", i, g.Node, g)
+	}
+
+	return b.String()
+}
+
+func TestCFG(t *testing.T) {
+	for _, testName := range cfgTestsRan {
+		jsCode, err := os.ReadFile("./js_tests/cfg/" + testName + ".js")
+		if err != nil {
+			panic(err)
+		}
+
+		expected, err := os.ReadFile("./js_tests/cfg/" + testName + ".txt")
+		if err != nil {
+			panic(err)
+		}
+
+		a, err := parser.ParseFile(string(jsCode))
+		if err != nil {
+			panic(err)
+		}
+
+		got := dumpCFG(dfa.BuildCFG(a))
+		if os.Getenv("CFG_WRITE") != "" {
+			os.WriteFile("./js_tests/cfg/"+testName+".txt", []byte(got), 0644)
+			continue
+		}
+
+		if got != string(expected) {
+			t.Fatalf("incorrect graph from test cfg/%s.js:\nexpected:\n%s\ngot:\n%s", testName, expected, got)
+		}
+
+		t.Logf("Test cfg/%s PASSED!\n\n", testName)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/civiledcode/javascribe/dfa"
+	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/parser"
+	"github.com/t14raptor/go-fast/token"
+)
+
+var dataflowTestsRan = []string{"01", "02", "03"}
+
+// sanitized is a custom forward analysis of the variables that only hold values returned by sanitize on every path.
+// Facts are sets of variables, where nil is the bottom of the lattice, so paths that haven't been reached don't empty the intersection.
+type sanitized struct {
+	cfg *dfa.CFG
+}
+
+func (s *sanitized) Bottom() dfa.VarSet {
+	return nil
+}
+
+func (s *sanitized) Join(a dfa.VarSet, b dfa.VarSet) dfa.VarSet {
+	if a == nil {
+		return b
+	}
+
+	if b == nil {
+		return a
+	}
+
+	joined := dfa.VarSet{}
+	for v := range a {
+		if b[v] {
+			joined[v] = true
+		}
+	}
+
+	return joined
+}
+
+func (s *sanitized) Equal(a dfa.VarSet, b dfa.VarSet) bool {
+	if (a == nil) != (b == nil) || len(a) != len(b) {
+		return false
+	}
+
+	for v := range a {
+		if !b[v] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *sanitized) Entry(g *dfa.FunctionGraph) dfa.VarSet {
+	return dfa.VarSet{}
+}
+
+func (s *sanitized) Transfer(n *dfa.GraphNode, in dfa.VarSet) dfa.VarSet {
+	out := dfa.VarSet{}
+	for v := range in {
+		out[v] = true
+	}
+
+	for _, item := range n.Items {
+		clean := s.sanitizes(item, out)
+		for _, a := range s.cfg.Accesses(item) {
+			if !a.Write || a.Deferred || !a.Direct() {
+				continue
+			}
+
+			delete(out, a.Var)
+			if clean {
+				out[a.Var] = true
+			}
+		}
+	}
+
+	return out
+}
+
+// sanitizes determines if the value an item assigns is returned by sanitize, or copied from a sanitized variable.
+func (s *sanitized) sanitizes(item *dfa.Item, set dfa.VarSet) bool {
+	if item.Expr == nil {
+		return false
+	}
+
+	value := item.Expr
+	if assign, ok := value.Expr.(*ast.AssignExpression); ok && item.Target == nil && assign.Operator == token.Assign {
+		value = assign.Right
+	}
+
+	switch v := value.Expr.(type) {
+	case *ast.CallExpression:
+		callee, ok := v.Callee.Expr.(*ast.Identifier)
+		return ok && callee.Name == "sanitize"
+	case *ast.Identifier:
+		return set[item.Variable(v.Name)]
+	}
+
+	return false
+}
+
+// varNames returns the names of a set of variables, sorted.
+func varNames(set dfa.VarSet) string {
+	names := []string{}
+	for v := range set {
+		names = append(names, v.String())
+	}
+
+	slices.Sort(names)
+	return strings.Join(names, " ")
+}
+
+// constantNames returns the known constants of a set of values, sorted.
+func constantNames(consts dfa.Constants) string {
+	names := []string{}
+	for v, c := range consts {
+		if !c.Known {
+			continue
+		}
+
+		value := fmt.Sprint(c.Value)
+		switch t := c.Value.(type) {
+		case string:
+			value = strconv.Quote(t)
+		case nil:
+			value = "null"
+		}
+
+		names = append(names, v.String()+"="+value)
+	}
+
+	slices.Sort(names)
+	return strings.Join(names, " ")
+}
+
+// dumpDataflow describes the facts every analysis computed for every node of a control flow graph.
+// Liveness is given before each node, and the other analyses after it.
+func dumpDataflow(c *dfa.CFG) string {
+	liveness := dfa.NewLiveness(c)
+	consts := dfa.NewConstantPropagation(c)
+	clean := &sanitized{cfg: c}
+
+	var b strings.Builder
+	for i, g := range c.Functions {
+		live := dfa.SolveBackward(g, liveness)
+		values := dfa.SolveForward(g, consts)
+		safe := dfa.SolveForward(g, clean)
+
+		fmt.Fprintf(&b, "graph %d %T\n", i, g.Node)
+		for _, n := range g.Nodes {
+			fmt.Fprintf(&b, "%d live [%s]", n.Id, varNames(live.In[n]))
+			if out, ok := values.Out[n]; ok {
+				fmt.Fprintf(&b, " consts [%s] sanitized [%s]", constantNames(out), varNames(safe.Out[n]))
+			}
+
+			b.WriteByte('\n')
+		}
+	}
+
+	return b.String()
+}
+
+func TestDataflow(t *testing.T) {
+	for _, testName := range dataflowTestsRan {
+		jsCode, err := os.ReadFile("./js_tests/dataflow/" + testName + ".js")
+		if err != nil {
+			panic(err)
+		}
+
+		a, err := parser.ParseFile(string(jsCode))
+		if err != nil {
+			panic(err)
+		}
+
+		got := dumpDataflow(dfa.BuildCFG(a))
+		if os.Getenv("DATAFLOW_WRITE") != "" {
+			os.WriteFile("./js_tests/dataflow/"+testName+".txt", []byte(got), 0644)
+			continue
+		}
+
+		expected, err := os.ReadFile("./js_tests/dataflow/" + testName + ".txt")
+		if err != nil {
+			panic(err)
+		}
+
+		if got != string(expected) {
+			t.Fatalf("incorrect facts from test dataflow/%s.js:\nexpected:\n%s\ngot:\n%s", testName, expected, got)
+		}
+
+		t.Logf("Test dataflow/%s PASSED!\n\n", testName)
+	}
+}
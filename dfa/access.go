@@ -0,0 +1,429 @@
+package dfa
+
+import (
+	"strconv"
+
+	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/token"
+)
+
+// Variable is a binding of a name, told apart from the bindings it shadows by the scope declaring it.
+type Variable struct {
+	Name string
+	// Scope is the id of the lexical scope declaring the binding, or -1 for a global that isn't declared anywhere.
+	Scope int
+}
+
+func (v Variable) String() string {
+	if v.Scope < 0 {
+		return v.Name
+	}
+
+	return v.Name + "@" + strconv.Itoa(v.Scope)
+}
+
+// Access is a read or write of a variable by an item.
+type Access struct {
+	// Id is the identifier the variable is accessed through.
+	Id  *ast.Identifier
+	Var Variable
+	// Path is the chain of property names accessed on the variable, or nil if the variable itself is accessed.
+	Path []string
+	// Write depicts if the access assigns the variable. Compound assignments and updates read the variable before writing it.
+	Write bool
+	// Weak depicts if the access is made by the default value of a destructuring target, which is only used if the source is undefined.
+	Weak bool
+	// Deferred depicts if the access is made by a function the item creates, which may run any time after the item.
+	Deferred bool
+	// Role is the role a read plays in the expression it's part of.
+	Role Role
+	// Value is the expression a write assigns, or nil if it's unknown.
+	Value *ast.Expression
+
+	// reassign depicts if the write assigns a variable declared elsewhere through an assignment or update.
+	reassign bool
+}
+
+// Direct determines if the access reads or writes the variable itself, and is certain to if the item runs.
+func (a Access) Direct() bool {
+	return a.Path == nil && !a.Weak
+}
+
+// accessSite is a point between the accesses of an item where it creates a function,
+// or where it evaluates a sub-expression lowered into items of its own.
+type accessSite struct {
+	// pos is the number of accesses of the item before the site.
+	pos   int
+	graph *FunctionGraph
+	expr  ast.Expr
+}
+
+// Variable returns the binding a name resolves to from the scope an item is evaluated in.
+func (item *Item) Variable(name string) Variable {
+	s := item.scope.resolve(name)
+	if s == nil {
+		return Variable{Name: name, Scope: -1}
+	}
+
+	return Variable{Name: name, Scope: s.id}
+}
+
+// Accesses returns the variables an item reads and writes, in the order it accesses them.
+// Lowered sub-expressions are left out, as they're accessed by their own items.
+// Reads and writes of properties are reported along with the variable they belong to, and destructuring targets
+// with a default value are written a second time by it, so only accesses that are Direct concern the variable itself.
+// Functions and classes created by the item access the variables their bodies read and write from outside of them,
+// which are reported as deferred accesses after the accesses of the item itself.
+func (c *CFG) Accesses(item *Item) []Access {
+	if list, ok := c.accesses[item]; ok {
+		return list
+	}
+
+	v := &accessVisitor{cfg: c, item: item}
+	v.V = v
+
+	switch n := item.Node.(type) {
+	case *ast.ForInto:
+		// The source is evaluated ahead of the loop, and every element of it is bound by the item.
+		v.bindPattern(item.Target, nil, false)
+	case *ast.FieldDefinition:
+		// Fields define a property of this rather than a variable.
+		v.operand(item.Expr)
+		if item.this != "" {
+			v.add(Access{Id: &ast.Identifier{Idx: n.Idx0(), Name: item.this}, Path: []string{elementName(n.Key)}, Write: true, Value: item.Expr})
+		}
+	case *ast.FunctionLiteral:
+		// The function is created by the item evaluating it, and only binds its own name.
+		v.add(Access{Id: n.Name, Write: true, Value: &ast.Expression{Expr: n.Name}})
+	case *ast.FunctionDeclaration:
+		v.add(Access{Id: n.Function.Name, Write: true, Value: item.Expr})
+	case *ast.VariableDeclarator:
+		v.operand(item.Expr)
+		if item.param {
+			v.bindPattern(item.Target, nil, false)
+			break
+		}
+
+		v.bindPattern(item.Target, item.Expr, false)
+	case *ast.ParameterList, *ast.CatchStatement:
+		v.bindPattern(item.Target, nil, false)
+	default:
+		if item.Expr != nil {
+			if cl, ok := item.Expr.Expr.(*ast.ClassLiteral); ok {
+				if id, ok := item.Target.(*ast.Identifier); ok {
+					v.add(Access{Id: id, Write: true, Value: item.Expr})
+				}
+
+				v.class(cl)
+				break
+			}
+		}
+
+		v.VisitExpression(item.Expr)
+	}
+
+	list := append(v.list, v.later...)
+	c.accesses[item] = list
+	c.sites[item] = v.sites
+	return list
+}
+
+// accessVisitor collects the accesses of an item, mirroring the positions the walk visits identifiers in.
+type accessVisitor struct {
+	ast.NoopVisitor
+	cfg  *CFG
+	item *Item
+	list []Access
+	// later holds the accesses made by the functions the item creates.
+	later []Access
+	sites []accessSite
+	// weak depicts if the accesses are made by the default value of a destructuring target.
+	weak bool
+}
+
+// add records an access of a variable.
+func (v *accessVisitor) add(a Access) {
+	a.Var = v.item.Variable(a.Id.Name)
+	a.Weak = a.Weak || v.weak
+	v.list = append(v.list, a)
+}
+
+// read records a read of a variable through an identifier.
+func (v *accessVisitor) read(id *ast.Identifier, role Role) {
+	v.add(Access{Id: id, Role: role})
+}
+
+// operand records the evaluation of the expression a declarator, parameter or field is initialized with.
+// Initializers that branch are lowered into items of their own, which evaluate it in place of the item.
+func (v *accessVisitor) operand(e *ast.Expression) {
+	if e == nil || e.Expr == nil {
+		return
+	}
+
+	if v.cfg.lowered[e.Expr] {
+		v.sites = append(v.sites, accessSite{pos: len(v.list), expr: e.Expr})
+		return
+	}
+
+	v.VisitExpression(e)
+}
+
+// bindPattern records the writes of every identifier bound by a target, along with the part of the source it's taken from,
+// and the reads made by its default values and property objects.
+// source is the expression the target takes its value from, or nil if it's unknown.
+// weak depicts if the target is bound by a default value, which only may replace its previous value.
+func (v *accessVisitor) bindPattern(target ast.Expr, source *ast.Expression, weak bool) {
+	switch t := target.(type) {
+	case *ast.Identifier:
+		v.add(Access{Id: t, Write: true, Weak: weak, Value: v.value(t, source)})
+	case *ast.AssignExpression:
+		v.VisitExpression(t.Right)
+		v.bindPattern(t.Left.Expr, source, weak)
+
+		outer := v.weak
+		v.weak = true
+		v.bindPattern(t.Left.Expr, t.Right, true)
+		v.weak = outer
+	case *ast.ArrayPattern:
+		for i := range t.Elements {
+			// Elisions don't bind anything.
+			if t.Elements[i].Expr == nil {
+				continue
+			}
+
+			v.bindPattern(t.Elements[i].Expr, elementSource(source, i), weak)
+		}
+
+		if t.Rest != nil && t.Rest.Expr != nil {
+			v.bindPattern(t.Rest.Expr, source, weak)
+		}
+	case *ast.ObjectPattern:
+		for i := range t.Properties {
+			switch p := t.Properties[i].Prop.(type) {
+			case *ast.PropertyShort:
+				s := propertySource(source, p.Name.Name)
+
+				if p.Initializer != nil && p.Initializer.Expr != nil {
+					v.VisitExpression(p.Initializer)
+					v.add(Access{Id: p.Name, Write: true, Weak: weak, Value: v.value(p.Name, s)})
+					v.add(Access{Id: p.Name, Write: true, Weak: true, Value: p.Initializer})
+					continue
+				}
+
+				v.add(Access{Id: p.Name, Write: true, Weak: weak, Value: v.value(p.Name, s)})
+			case *ast.PropertyKeyed:
+				var s *ast.Expression
+				if key, ok := p.Key.Expr.(*ast.StringLiteral); ok && !p.Computed {
+					s = propertySource(source, key.Value)
+				} else {
+					// Computed keys are evaluated before the property is bound.
+					v.VisitExpression(p.Key)
+					s = computedSource(source, p.Key)
+				}
+
+				v.bindPattern(p.Value.Expr, s, weak)
+			}
+		}
+
+		if t.Rest != nil {
+			v.bindPattern(t.Rest, source, weak)
+		}
+	case *ast.MemberExpression, *ast.PrivateDotExpression:
+		v.visitObject(t)
+		v.writePath(t, source, weak)
+	}
+}
+
+// value returns the value an identifier bound by a target takes, given the part of the source it's taken from.
+// Parameters and catch parameters are their own value, and the bindings of a for-in or for-of loop take the source of the loop.
+func (v *accessVisitor) value(id *ast.Identifier, source *ast.Expression) *ast.Expression {
+	if source != nil {
+		return source
+	}
+
+	if v.item.param {
+		return &ast.Expression{Expr: id}
+	}
+
+	if _, ok := v.item.Node.(*ast.ForInto); ok {
+		return v.item.Expr
+	}
+
+	return nil
+}
+
+// visitObject records the reads of the object of a member expression and its computed key, which are evaluated before the property is accessed.
+func (v *accessVisitor) visitObject(e ast.Expr) {
+	switch t := e.(type) {
+	case *ast.MemberExpression:
+		v.reference(t.Object, ObjectRole)
+		if c, ok := t.Property.Prop.(*ast.ComputedProperty); ok {
+			v.VisitExpression(c.Expr)
+		}
+	case *ast.PrivateDotExpression:
+		v.reference(t.Left, ObjectRole)
+	}
+}
+
+// reference records the reads of an expression that plays a role other than being read, such as a callee.
+func (v *accessVisitor) reference(e *ast.Expression, role Role) {
+	if id, ok := e.Expr.(*ast.Identifier); ok && !v.cfg.lowered[id] {
+		v.read(id, role)
+		return
+	}
+
+	v.VisitExpression(e)
+}
+
+// readPath records a read of the property accessed by a member expression.
+func (v *accessVisitor) readPath(e ast.Expr) {
+	if base, path, ok := accessPath(e, v.item.this); ok {
+		v.add(Access{Id: base, Path: path, Role: PropertyRole})
+	}
+}
+
+// writePath records a write of the property accessed by a member expression.
+// value is the expression assigned to it, or nil if it's unknown.
+// weak depicts if the write only may replace the previous value of the property.
+func (v *accessVisitor) writePath(e ast.Expr, value *ast.Expression, weak bool) {
+	if base, path, ok := accessPath(e, v.item.this); ok {
+		v.add(Access{Id: base, Path: path, Write: true, Weak: weak, Value: value})
+	}
+}
+
+// class records the constructor a class creates along with its binding, while its methods are created by items of their own.
+func (v *accessVisitor) class(c *ast.ClassLiteral) {
+	v.created(v.cfg.graphs[c])
+}
+
+// created records a function created by the item, along with the accesses it makes.
+// g is the graph of the function, or nil if it has none.
+func (v *accessVisitor) created(g *FunctionGraph) {
+	if g == nil {
+		return
+	}
+
+	v.sites = append(v.sites, accessSite{pos: len(v.list), graph: g})
+	v.deferred(g)
+}
+
+// deferred records the accesses a function makes to the variables declared outside of it.
+// g is the graph of the function, or nil if it has none.
+func (v *accessVisitor) deferred(g *FunctionGraph) {
+	if g == nil {
+		return
+	}
+
+	outer := make(map[int]bool)
+	for s := g.Entry.scope.parent; s != nil; s = s.parent {
+		outer[s.id] = true
+	}
+
+	for _, n := range g.Nodes {
+		for _, item := range n.Items {
+			for _, a := range v.cfg.Accesses(item) {
+				if a.Var.Scope >= 0 && !outer[a.Var.Scope] {
+					continue
+				}
+
+				a.Deferred = true
+				v.later = append(v.later, a)
+			}
+		}
+	}
+}
+
+func (v *accessVisitor) VisitExpression(n *ast.Expression) {
+	if n == nil || n.Expr == nil {
+		return
+	}
+
+	// Only the expression of an item evaluating an expression is evaluated by the item itself.
+	if v.cfg.lowered[n.Expr] && (v.item.Node != nil || v.item.Expr == nil || n.Expr != v.item.Expr.Expr) {
+		v.sites = append(v.sites, accessSite{pos: len(v.list), expr: n.Expr})
+		return
+	}
+
+	n.VisitChildrenWith(v)
+}
+
+func (v *accessVisitor) VisitIdentifier(n *ast.Identifier) {
+	v.read(n, ReadRole)
+}
+
+func (v *accessVisitor) VisitAssignExpression(n *ast.AssignExpression) {
+	switch left := n.Left.Expr.(type) {
+	case *ast.Identifier:
+		if n.Operator != token.Assign {
+			v.read(left, UpdateRole)
+		}
+
+		v.VisitExpression(n.Right)
+		v.add(Access{Id: left, Write: true, Value: n.Right, reassign: true})
+	case *ast.MemberExpression, *ast.PrivateDotExpression:
+		v.visitObject(left)
+		if n.Operator != token.Assign {
+			v.readPath(left)
+		}
+
+		v.VisitExpression(n.Right)
+		v.writePath(left, n.Right, false)
+	case *ast.ArrayPattern, *ast.ObjectPattern:
+		v.VisitExpression(n.Right)
+		v.bindPattern(left, n.Right, false)
+	default:
+		v.VisitExpression(n.Left)
+		v.VisitExpression(n.Right)
+	}
+}
+
+func (v *accessVisitor) VisitUpdateExpression(n *ast.UpdateExpression) {
+	switch operand := n.Operand.Expr.(type) {
+	case *ast.Identifier:
+		v.read(operand, UpdateRole)
+		v.add(Access{Id: operand, Write: true, Value: &ast.Expression{Expr: n}, reassign: true})
+	case *ast.MemberExpression, *ast.PrivateDotExpression:
+		v.visitObject(operand)
+		v.readPath(operand)
+		v.writePath(operand, &ast.Expression{Expr: n}, false)
+	default:
+		n.VisitChildrenWith(v)
+	}
+}
+
+func (v *accessVisitor) VisitCallExpression(n *ast.CallExpression) {
+	v.reference(n.Callee, CallRole)
+	v.VisitExpressions(&n.ArgumentList)
+}
+
+func (v *accessVisitor) VisitNewExpression(n *ast.NewExpression) {
+	v.reference(n.Callee, CallRole)
+	v.VisitExpressions(&n.ArgumentList)
+}
+
+func (v *accessVisitor) VisitMemberExpression(n *ast.MemberExpression) {
+	v.visitObject(n)
+	v.readPath(n)
+}
+
+func (v *accessVisitor) VisitPrivateDotExpression(n *ast.PrivateDotExpression) {
+	v.visitObject(n)
+	v.readPath(n)
+}
+
+func (v *accessVisitor) VisitFunctionLiteral(n *ast.FunctionLiteral) {
+	v.created(v.cfg.graphs[n])
+}
+
+func (v *accessVisitor) VisitArrowFunctionLiteral(n *ast.ArrowFunctionLiteral) {
+	v.created(v.cfg.graphs[n])
+}
+
+// VisitClassLiteral expects classes to be lowered into items of their own.
+func (v *accessVisitor) VisitClassLiteral(n *ast.ClassLiteral) {}
+
+// VisitMetaProperty skips meta properties such as new.target, which are keywords rather than references.
+func (v *accessVisitor) VisitMetaProperty(n *ast.MetaProperty) {}
+
+// VisitPrivateIdentifier skips private names, which belong to a class rather than a scope.
+func (v *accessVisitor) VisitPrivateIdentifier(n *ast.PrivateIdentifier) {}
@@ -1,5 +1,1670 @@
 package dfa
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/token"
+)
+
+// EdgeKind is the kind of control flow an edge follows.
+type EdgeKind int
+
+const (
+	// FallThroughEdge continues to the next block unconditionally.
+	FallThroughEdge EdgeKind = iota
+	// TrueEdge is taken when the condition ending its block holds.
+	// For ?? and optional chains, the condition is the value being null or undefined.
+	TrueEdge
+	// FalseEdge is taken when the condition ending its block doesn't hold.
+	FalseEdge
+	// BackEdge leads from the end of a loop body, or a do-while loop's passing test, back to the start of the loop.
+	BackEdge
+	// BreakEdge leads from a break statement to the statement following its target.
+	BreakEdge
+	// ContinueEdge leads from a continue statement to the next iteration of its loop.
+	ContinueEdge
+	// ExceptionEdge leads from a block that may throw to the closest catch or finally block, or to the exit of the function.
+	// The block may throw before any of its items completes, so only the code reaching the start of the block has run.
+	ExceptionEdge
+	// ReturnEdge leads from a return statement to the exit of the function.
+	ReturnEdge
+)
+
+func (k EdgeKind) String() string {
+	switch k {
+	case TrueEdge:
+		return "true"
+	case FalseEdge:
+		return "false"
+	case BackEdge:
+		return "back"
+	case BreakEdge:
+		return "break"
+	case ContinueEdge:
+		return "continue"
+	case ExceptionEdge:
+		return "exception"
+	case ReturnEdge:
+		return "return"
+	}
+
+	return "fallthrough"
+}
+
+// NodeKind is the kind of a node in a control flow graph.
+type NodeKind int
+
+const (
+	// BlockNode is a basic block of code.
+	BlockNode NodeKind = iota
+	// EntryNode is the empty node every function starts at.
+	EntryNode
+	// ExitNode is the empty node every function returns or throws to.
+	ExitNode
+)
+
+// Edge is a transfer of control between two nodes.
+type Edge struct {
+	From *GraphNode
+	To   *GraphNode
+	Kind EdgeKind
+}
+
+// Item is a single step of a basic block, either evaluating an expression or binding a target.
+// Sub-expressions that Lowered reports were already evaluated by earlier items, and only their value is used.
+type Item struct {
+	// Expr is the expression the item evaluates, or the value a binding is initialized with.
+	// It's nil for bindings with an unknown value, such as parameters without a default value.
+	Expr *ast.Expression
+	// Target is the target a binding defines, or nil if the item only evaluates Expr.
+	Target ast.Expr
+	// Kind is the keyword a binding is declared with, which is token.Var for parameters and token.Let for catch parameters.
+	// Bindings that assign to existing variables, such as the binding of for (x of xs), use token.Assign,
+	// and field definitions, which define a property of this, have no kind.
+	Kind token.Token
+	// Node is the node a binding comes from, such as the *ast.VariableDeclarator it declares.
+	// Finally blocks are built once for every path leaving them, so the items of their copies share the same nodes.
+	Node ast.VisitableNode
+
+	// scope is the lexical scope the item is evaluated in.
+	scope *lexicalScope
+	// cond depicts if the item only runs depending on a test evaluated outside of the block it's in.
+	cond bool
+	// this is the name of the binding this refers to in the item, or an empty string if it can't be resolved.
+	this string
+	// param depicts if the item binds a parameter, which takes the argument the function is called with as its value.
+	param bool
+	// loops holds the loops of the graph the item is inside of, outermost first.
+	loops []*cfgLoop
+	// index is the position of the item among the items of its graph, in the order they're written in.
+	// The copies of a finally block each take positions of their own.
+	index int
+	// update depicts if the item evaluates the update of the innermost loop around it, which is written ahead of the body it runs after.
+	update bool
+	// operands holds the items evaluating every operand of the item lowered ahead of it, which it evaluates in between its own accesses.
+	operands map[ast.Expr][]*Item
+	// operand depicts if the item evaluates an operand of another item, which evaluates it in place.
+	operand bool
+}
+
+// GraphNode is a node of a control flow graph.
 type GraphNode struct {
+	// Id is the position of the node in its graph, in the order the nodes were created.
+	Id   int
+	Kind NodeKind
+	// Items holds the steps of a basic block, in the order they're evaluated.
+	// A block with a TrueEdge and a FalseEdge branches on the value of its last item,
+	// or on the value of the short-circuiting expression ending in it if it has no items.
+	Items []*Item
+	// Children holds the successors of the node, in the same order as Edges.
 	Children []*GraphNode
+	// Edges holds the edges leaving the node.
+	Edges []*Edge
+	// Incoming holds the edges entering the node.
+	Incoming []*Edge
+	Graph    *FunctionGraph
+
+	// scope is the lexical scope the node starts in.
+	scope *lexicalScope
+}
+
+// FunctionGraph is the control flow graph of a single function, or of the top level of a program.
+type FunctionGraph struct {
+	// Node is the node the graph is built from, which is an *ast.Program, *ast.FunctionLiteral or *ast.ArrowFunctionLiteral.
+	// The graph of a class constructor, which also initializes the instance fields, is built from the *ast.ClassLiteral.
+	Node  ast.VisitableNode
+	Entry *GraphNode
+	Exit  *GraphNode
+	// Nodes holds every node of the graph, in the order they were created.
+	Nodes []*GraphNode
+	// Parent is the graph of the function the function is created in, or nil for the program.
+	Parent *FunctionGraph
+
+	// hoisted holds the items binding the function declarations hoisted to the top of the function around a function declaration,
+	// which is created along with them, or nil if it isn't hoisted.
+	hoisted []*Item
+	// items is the number of items of the graph.
+	items int
+}
+
+// cfgLoop is a loop of a graph.
+type cfgLoop struct {
+	// scope is the lexical scope the loop starts in.
+	scope *lexicalScope
+}
+
+// CFG holds the control flow graphs of a program and of every function in it.
+type CFG struct {
+	Program *FunctionGraph
+	// Functions holds every graph, in the order the functions are created, starting with the program.
+	Functions []*FunctionGraph
+
+	graphs  map[ast.VisitableNode]*FunctionGraph
+	lowered map[ast.Expr]bool
+	// accesses holds the accesses of every item, once they're collected.
+	accesses map[*Item][]Access
+	// sites holds the functions every item creates, once its accesses are collected.
+	sites map[*Item][]accessSite
+	// scopes is the number of lexical scopes created.
+	scopes int
+}
+
+// lexicalScope is a scope that bindings are declared in.
+// Items refer to bindings by name, which resolve to the closest enclosing scope declaring the name.
+type lexicalScope struct {
+	id     int
+	parent *lexicalScope
+	// names holds every name declared in the scope.
+	names map[string]bool
+	// lexicals holds the let, const and class bindings of the scope, which are uninitialized whenever the scope is entered.
+	lexicals []*ast.Identifier
+	// vars holds the names declared with var in the scope of a function, which start out undefined.
+	vars []string
+	// graph is the graph the scope is in.
+	graph *FunctionGraph
+}
+
+// resolve returns the closest scope declaring a name, or nil if it's a global that isn't declared anywhere.
+func (s *lexicalScope) resolve(name string) *lexicalScope {
+	for ; s != nil; s = s.parent {
+		if s.names[name] {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// Graph returns the graph of a function, or nil if there's none.
+// fn is the *ast.Program, *ast.FunctionLiteral, *ast.ArrowFunctionLiteral or *ast.ClassLiteral the graph is built from.
+// The function literal of an explicit constructor also returns the graph of its class.
+func (c *CFG) Graph(fn ast.VisitableNode) *FunctionGraph {
+	return c.graphs[fn]
+}
+
+// Lowered determines if an expression is evaluated by items of its own, rather than along with the item it's part of.
+func (c *CFG) Lowered(e ast.Expr) bool {
+	return c.lowered[e]
+}
+
+// written returns the items of a graph in the order they're written in, leaving out the ones evaluating operands of other items.
+func (g *FunctionGraph) written() []*Item {
+	var items []*Item
+	for _, n := range g.Nodes {
+		for _, item := range n.Items {
+			if !item.operand {
+				items = append(items, item)
+			}
+		}
+	}
+
+	slices.SortFunc(items, func(a *Item, b *Item) int {
+		return a.index - b.index
+	})
+
+	return items
+}
+
+func (g *FunctionGraph) String() string {
+	var b strings.Builder
+	for _, n := range g.Nodes {
+		switch n.Kind {
+		case EntryNode:
+			fmt.Fprintf(&b, "%d entry", n.Id)
+		case ExitNode:
+			fmt.Fprintf(&b, "%d exit", n.Id)
+		default:
+			fmt.Fprintf(&b, "%d block (%d items)", n.Id, len(n.Items))
+		}
+
+		for _, e := range n.Edges {
+			fmt.Fprintf(&b, " %s->%d", e.Kind, e.To.Id)
+		}
+
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
+
+// BuildCFG builds the control flow graph of a program.
+// Every function is built into its own graph, and short-circuiting expressions branch within the graph they're evaluated in.
+func BuildCFG(a *ast.Program) *CFG {
+	c := &CFG{
+		graphs:   make(map[ast.VisitableNode]*FunctionGraph),
+		lowered:  make(map[ast.Expr]bool),
+		accesses: make(map[*Item][]Access),
+		sites:    make(map[*Item][]accessSite),
+	}
+
+	b := &cfgBuilder{cfg: c, this: "this"}
+	c.Program = b.function(a, functionScope(nil, nil, a.Body), func() {
+		b.statements(a.Body)
+	})
+
+	return c
+}
+
+// cfgBuilder lowers statements and expressions into the blocks of a control flow graph.
+type cfgBuilder struct {
+	cfg   *CFG
+	graph *FunctionGraph
+	// current is the block code is being added to.
+	current *GraphNode
+
+	// targets holds the enclosing statements that jumps can lead to, and the finally blocks they have to run on the way.
+	targets []*cfgTarget
+	// labels holds the labels waiting to be attached to the next loop or switch statement.
+	labels []string
+	// handler is the closest catch or finally block exceptions are thrown to, or nil if they leave the function.
+	handler *GraphNode
+	// chains holds the blocks of every enclosing optional chain that short-circuit to its end.
+	chains [][]*GraphNode
+	// scope is the lexical scope code is being added to.
+	scope *lexicalScope
+
+	// conds is the number of conditional regions code is being added to, and top depicts if the innermost region is one of them.
+	conds int
+	top   bool
+	// this is the name of the binding this refers to.
+	this string
+	// loops holds the loops of the current graph code is being added to, outermost first.
+	loops []*cfgLoop
+	// update depicts if the code being added is the update of a for loop.
+	update bool
+	// operand collects the items evaluating the operand being lowered ahead of the item it belongs to, or is nil outside of operands.
+	operand *[]*Item
+}
+
+// cfgTarget is a statement that break and continue statements can jump to, or a finally block they have to run on the way.
+type cfgTarget struct {
+	labels []string
+	// loop depicts if continue statements can jump to the target.
+	loop bool
+	// breakable depicts if break statements without a label can jump to the target.
+	breakable  bool
+	breakTo    *GraphNode
+	continueTo *GraphNode
+	// finally is the finally block of a try statement, which is set instead of a jump target.
+	finally *cfgFinally
+}
+
+// cfgFinally is a finally block, which every path leaving its try statement runs on the way.
+// The block is built once for every place those paths continue to, so each of them continues with the code that reached it.
+type cfgFinally struct {
+	// thrown is the start of the copy that exceptions are thrown to, which rethrows them afterwards.
+	thrown *GraphNode
+	// jumps holds the jumps leaving the try statement.
+	jumps []*cfgJump
+}
+
+// cfgJump is a jump leaving a try statement through its finally block.
+type cfgJump struct {
+	from *GraphNode
+	to   *GraphNode
+	kind EdgeKind
+	// crossed is the number of targets the jump leaves outside of the try statement.
+	crossed int
+}
+
+// function builds the graph of a function, restoring the state of the enclosing graph afterwards.
+// Functions inside of a finally block are only built once, along with the first copy of the block.
+// fn is the node the graph is built from.
+// scope is the scope of the function, which every node of the graph is inside of.
+// build should lower the body of the function into the current block.
+func (b *cfgBuilder) function(fn ast.VisitableNode, scope *lexicalScope, build func()) *FunctionGraph {
+	if g := b.cfg.graphs[fn]; g != nil {
+		return g
+	}
+
+	g := &FunctionGraph{Node: fn, Parent: b.graph}
+	b.cfg.graphs[fn] = g
+	b.cfg.Functions = append(b.cfg.Functions, g)
+
+	// Jumps and exceptions can't leave the function they're in.
+	graph, current, targets, labels, handler, chains, outer := b.graph, b.current, b.targets, b.labels, b.handler, b.chains, b.scope
+	loops, update, operand := b.loops, b.update, b.operand
+	b.graph, b.targets, b.labels, b.handler, b.chains = g, nil, nil, nil, nil
+	b.loops, b.update, b.operand = nil, false, nil
+	b.enter(scope)
+	defer b.nest(false)()
+
+	g.Entry = b.node(EntryNode)
+	g.Exit = b.node(ExitNode)
+
+	b.current = b.block()
+	b.edge(g.Entry, b.current, FallThroughEdge)
+
+	build()
+	b.edge(b.current, g.Exit, FallThroughEdge)
+
+	b.graph, b.current, b.targets, b.labels, b.handler, b.chains, b.scope = graph, current, targets, labels, handler, chains, outer
+	b.loops, b.update, b.operand = loops, update, operand
+	return g
+}
+
+// functionScope returns the scope of a function, which declares its name, parameters and hoisted declarations,
+// along with the let, const and class bindings at the top of its body.
+// name is the name of a function expression, which is bound inside of the function itself, or nil if there's none.
+// params is the parameter list of the function, or nil for a program.
+// body is the list of statements in the body of the function.
+func functionScope(name *ast.Identifier, params *ast.ParameterList, body ast.Statements) *lexicalScope {
+	var names []string
+	if name != nil && name.Name != "" {
+		names = append(names, name.Name)
+	}
+
+	if params != nil {
+		for i := range params.List {
+			for _, id := range boundIdentifiers(params.List[i].Target.Target) {
+				names = append(names, id.Name)
+			}
+		}
+
+		for _, id := range boundIdentifiers(params.Rest) {
+			names = append(names, id.Name)
+		}
+	}
+
+	h := newHoistVisitor()
+	body.VisitWith(h)
+	names = append(names, h.vars...)
+	names = append(names, h.functions...)
+
+	s := newLexicalScope(names, lexicalDeclarations(body))
+	s.vars = h.vars
+	return s
+}
+
+// newLexicalScope creates a scope declaring names, where lexicals are the let, const and class bindings among them.
+// Lexical bindings are declared along with the other names.
+func newLexicalScope(names []string, lexicals []*ast.Identifier) *lexicalScope {
+	s := &lexicalScope{
+		names:    make(map[string]bool),
+		lexicals: lexicals,
+	}
+
+	for _, name := range names {
+		s.names[name] = true
+	}
+
+	for _, id := range lexicals {
+		s.names[id.Name] = true
+	}
+
+	return s
+}
+
+// enter starts a scope inside of the current one.
+func (b *cfgBuilder) enter(s *lexicalScope) {
+	s.id = b.cfg.scopes
+	s.parent = b.scope
+	s.graph = b.graph
+	b.cfg.scopes++
+	b.scope = s
+}
+
+// leaveScope ends the current lexical scope.
+func (b *cfgBuilder) leaveScope() {
+	b.scope = b.scope.parent
+}
+
+// nest starts a region of code inside of the current one, and returns a function ending it again.
+// cond depicts if the region only runs depending on a test evaluated before it, along with the test itself.
+func (b *cfgBuilder) nest(cond bool) func() {
+	conds, top := b.conds, b.top
+	if cond {
+		b.conds++
+	}
+
+	b.top = cond
+	return func() {
+		b.conds, b.top = conds, top
+	}
+}
+
+// startLoop starts a loop, which ends again once the returned function is called.
+func (b *cfgBuilder) startLoop() func() {
+	l := &cfgLoop{scope: b.scope}
+	b.loops = append(b.loops[:len(b.loops):len(b.loops)], l)
+	return func() {
+		b.loops = b.loops[:len(b.loops)-1]
+	}
+}
+
+// body lowers a block of statements in a lexical scope of its own.
+func (b *cfgBuilder) body(stmts ast.Statements) {
+	defer b.nest(false)()
+	b.enter(newLexicalScope(nil, lexicalDeclarations(stmts)))
+	b.statements(stmts)
+	b.leaveScope()
+}
+
+// identifierNames returns the names of a list of identifiers.
+func identifierNames(ids []*ast.Identifier) []string {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = id.Name
+	}
+
+	return names
+}
+
+// node creates a node in the current graph.
+func (b *cfgBuilder) node(kind NodeKind) *GraphNode {
+	n := &GraphNode{
+		Id:    len(b.graph.Nodes),
+		Kind:  kind,
+		Graph: b.graph,
+		scope: b.scope,
+	}
+
+	b.graph.Nodes = append(b.graph.Nodes, n)
+	return n
+}
+
+// block creates a basic block, which may throw to the closest handler.
+func (b *cfgBuilder) block() *GraphNode {
+	n := b.node(BlockNode)
+	if b.handler != nil {
+		b.throw(n)
+	}
+
+	return n
+}
+
+// next continues the current block into a new one.
+func (b *cfgBuilder) next() {
+	n := b.block()
+	b.edge(b.current, n, FallThroughEdge)
+	b.current = n
+}
+
+// edge adds an edge between two nodes, unless it already exists.
+func (b *cfgBuilder) edge(from *GraphNode, to *GraphNode, kind EdgeKind) {
+	for _, e := range from.Edges {
+		if e.To == to && e.Kind == kind {
+			return
+		}
+	}
+
+	e := &Edge{From: from, To: to, Kind: kind}
+	from.Edges = append(from.Edges, e)
+	from.Children = append(from.Children, to)
+	to.Incoming = append(to.Incoming, e)
+}
+
+// throw adds an edge from a node to the closest handler, or to the exit of the function.
+func (b *cfgBuilder) throw(from *GraphNode) {
+	if b.handler == nil {
+		b.edge(from, b.graph.Exit, ExceptionEdge)
+		return
+	}
+
+	b.edge(from, b.handler, ExceptionEdge)
+}
+
+// emit adds an item to the current block, and builds the graphs of the functions it creates.
+func (b *cfgBuilder) emit(item *Item) {
+	// Inside of a try statement, every item may throw with the definitions of the items before it.
+	if b.handler != nil && len(b.current.Items) > 0 {
+		b.next()
+	}
+
+	item.scope = b.scope
+	item.cond = b.conds > 1 || b.conds == 1 && !b.top
+	item.this = b.this
+	item.loops = b.loops
+	item.index = b.graph.items
+	item.update = b.update
+	b.graph.items++
+	b.current.Items = append(b.current.Items, item)
+	if b.operand != nil {
+		*b.operand = append(*b.operand, item)
+	}
+
+	if item.Expr != nil {
+		b.functions(item.Expr)
+	}
+
+	if item.Target != nil {
+		b.functions(item.Target)
+	}
+}
+
+// functions builds the graph of every function created by a node, besides the ones in lowered expressions.
+func (b *cfgBuilder) functions(n ast.VisitableNode) {
+	f := &functionFinder{cfg: b.cfg}
+	f.V = f
+	n.VisitWith(f)
+
+	for _, fn := range f.found {
+		if b.cfg.graphs[fn] != nil {
+			continue
+		}
+
+		switch t := fn.(type) {
+		case *ast.FunctionLiteral:
+			b.functionLiteral(t, true)
+		case *ast.ArrowFunctionLiteral:
+			var body ast.Statements
+			if block, ok := t.Body.Body.(*ast.BlockStatement); ok {
+				body = block.List
+			}
+
+			b.function(t, functionScope(nil, &t.ParameterList, body), func() {
+				b.parameters(&t.ParameterList)
+
+				switch body := t.Body.Body.(type) {
+				case *ast.BlockStatement:
+					b.statements(body.List)
+				case *ast.Expression:
+					// Expression bodies return their value.
+					b.value(body)
+					b.edge(b.current, b.graph.Exit, ReturnEdge)
+					b.current = b.block()
+				}
+			})
+		}
+	}
+}
+
+// functionLiteral builds the graph of a function literal.
+// expression depicts if the function is a function expression, whose name is bound inside of the function itself.
+func (b *cfgBuilder) functionLiteral(f *ast.FunctionLiteral, expression bool) *FunctionGraph {
+	var name *ast.Identifier
+	if expression {
+		name = f.Name
+	}
+
+	// Every function except arrow functions binds the arguments it's called with.
+	scope := functionScope(name, &f.ParameterList, f.Body.List)
+	scope.names["arguments"] = true
+
+	return b.function(f, scope, func() {
+		if expression && f.Name != nil && f.Name.Name != "" {
+			b.emit(&Item{Expr: &ast.Expression{Expr: f}, Target: f.Name, Kind: token.Function, Node: f})
+		}
+
+		b.parameters(&f.ParameterList)
+		b.statements(f.Body.List)
+	})
+}
+
+// parameters binds the parameters of a function, in order.
+func (b *cfgBuilder) parameters(params *ast.ParameterList) {
+	for i := range params.List {
+		p := &params.List[i]
+		if p.Initializer != nil && b.hasFlow(p.Initializer) {
+			b.value(p.Initializer)
+		}
+
+		b.emit(&Item{Expr: p.Initializer, Target: p.Target.Target, Kind: token.Var, Node: p, param: true})
+	}
+
+	if params.Rest != nil {
+		b.emit(&Item{Target: params.Rest, Kind: token.Var, Node: params, param: true})
+	}
+}
+
+// functionFinder collects the functions created by a node, without entering them or any lowered expression.
+type functionFinder struct {
+	ast.NoopVisitor
+	cfg   *CFG
+	found []ast.VisitableNode
+}
+
+func (f *functionFinder) VisitExpression(n *ast.Expression) {
+	if n.Expr == nil || f.cfg.lowered[n.Expr] {
+		return
+	}
+
+	n.VisitChildrenWith(f)
+}
+
+func (f *functionFinder) VisitFunctionLiteral(n *ast.FunctionLiteral) {
+	f.found = append(f.found, n)
+}
+
+func (f *functionFinder) VisitArrowFunctionLiteral(n *ast.ArrowFunctionLiteral) {
+	f.found = append(f.found, n)
+}
+
+func (f *functionFinder) VisitClassLiteral(n *ast.ClassLiteral) {}
+
+// statements lowers a statement list, after binding the function declarations hoisted to the top of it.
+func (b *cfgBuilder) statements(stmts ast.Statements) {
+	b.hoist(stmts)
+	b.lower(stmts)
+}
+
+// hoist builds the graphs of the function declarations in a statement list.
+// Declarations at the top of a function bind their names before anything else in it runs, while the ones in blocks bind them where they're written.
+func (b *cfgBuilder) hoist(stmts ast.Statements) {
+	top := b.scope == b.graph.Entry.scope
+
+	var hoisted []*FunctionGraph
+	var items []*Item
+	for i := range stmts {
+		if f, ok := stmts[i].Stmt.(*ast.FunctionDeclaration); ok && f.Function.Name != nil {
+			g := b.functionLiteral(f.Function, false)
+			if top {
+				item := &Item{Expr: &ast.Expression{Expr: f.Function}, Target: f.Function.Name, Kind: token.Function, Node: f}
+				b.emit(item)
+				hoisted, items = append(hoisted, g), append(items, item)
+			}
+		}
+	}
+
+	for _, g := range hoisted {
+		g.hoisted = items
+	}
+}
+
+// lower lowers the statements of a list in order, creating every function declaration where it's written.
+func (b *cfgBuilder) lower(stmts ast.Statements) {
+	for i := range stmts {
+		if f, ok := stmts[i].Stmt.(*ast.FunctionDeclaration); ok && f.Function.Name != nil {
+			if b.cfg.graphs[f.Function].hoisted == nil {
+				b.emit(&Item{Expr: &ast.Expression{Expr: f.Function}, Target: f.Function.Name, Kind: token.Function, Node: f})
+			}
+
+			b.emit(&Item{Expr: &ast.Expression{Expr: f.Function}})
+		}
+
+		b.statement(&stmts[i])
+	}
+}
+
+// statement lowers a single statement into the current block, continuing into new blocks wherever control flow branches.
+func (b *cfgBuilder) statement(s *ast.Statement) {
+	switch n := s.Stmt.(type) {
+	case *ast.BlockStatement:
+		b.body(n.List)
+	case *ast.ExpressionStatement:
+		b.value(n.Expression)
+	case *ast.VariableDeclaration:
+		b.declaration(n)
+	case *ast.ClassDeclaration:
+		b.class(n.Class, n)
+	case *ast.IfStatement:
+		b.ifStatement(n)
+	case *ast.ForStatement:
+		b.forStatement(n)
+	case *ast.ForInStatement:
+		b.forEach(n.Into, n.Source, n.Body)
+	case *ast.ForOfStatement:
+		b.forEach(n.Into, n.Source, n.Body)
+	case *ast.WhileStatement:
+		b.whileStatement(n)
+	case *ast.DoWhileStatement:
+		b.doWhileStatement(n)
+	case *ast.SwitchStatement:
+		b.switchStatement(n)
+	case *ast.TryStatement:
+		b.tryStatement(n)
+	case *ast.LabelledStatement:
+		b.labelledStatement(n)
+	case *ast.WithStatement:
+		b.value(n.Object)
+		b.statement(n.Body)
+	case *ast.BreakStatement:
+		b.jump(n.Label, false)
+	case *ast.ContinueStatement:
+		b.jump(n.Label, true)
+	case *ast.ReturnStatement:
+		if n.Argument != nil {
+			b.value(n.Argument)
+		}
+
+		b.leave(b.graph.Exit, ReturnEdge, len(b.targets))
+	case *ast.ThrowStatement:
+		// The exception is thrown once its argument is evaluated.
+		b.value(n.Argument)
+		b.next()
+		b.throw(b.current)
+
+		// Nothing after a throw statement is reachable.
+		b.unreachable()
+	}
+}
+
+// declaration lowers a variable declaration, binding its declarators in order.
+func (b *cfgBuilder) declaration(n *ast.VariableDeclaration) {
+	for i := range n.List {
+		d := &n.List[i]
+		if d.Initializer != nil && b.hasFlow(d.Initializer) {
+			b.value(d.Initializer)
+		}
+
+		b.emit(&Item{Expr: d.Initializer, Target: d.Target.Target, Kind: n.Token, Node: d})
+	}
+}
+
+func (b *cfgBuilder) ifStatement(n *ast.IfStatement) {
+	end := b.nest(true)
+	b.value(n.Test)
+	test := b.current
+
+	b.current = b.block()
+	b.edge(test, b.current, TrueEdge)
+	b.statement(n.Consequent)
+	consequent := b.current
+	end()
+
+	alternate := test
+	if n.Alternate != nil {
+		// An else if statement is a statement of its own, rather than a branch of the one it follows.
+		end := func() {}
+		if _, ok := n.Alternate.Stmt.(*ast.IfStatement); !ok {
+			end = b.nest(true)
+		}
+
+		b.current = b.block()
+		b.edge(test, b.current, FalseEdge)
+		b.statement(n.Alternate)
+		alternate = b.current
+		end()
+	}
+
+	b.current = b.block()
+	b.edge(consequent, b.current, FallThroughEdge)
+	if n.Alternate != nil {
+		b.edge(alternate, b.current, FallThroughEdge)
+	} else {
+		b.edge(test, b.current, FalseEdge)
+	}
+}
+
+// takeLabels returns the labels waiting to be attached to a statement, and clears them.
+func (b *cfgBuilder) takeLabels() []string {
+	labels := b.labels
+	b.labels = nil
+	return labels
+}
+
+// loop pushes the jump target of a loop, lowers its body, and pops it again.
+func (b *cfgBuilder) loop(labels []string, breakTo *GraphNode, continueTo *GraphNode, body *ast.Statement) {
+	b.targets = append(b.targets, &cfgTarget{
+		labels:     labels,
+		loop:       true,
+		breakable:  true,
+		breakTo:    breakTo,
+		continueTo: continueTo,
+	})
+
+	b.statement(body)
+	b.targets = b.targets[:len(b.targets)-1]
+}
+
+func (b *cfgBuilder) forStatement(n *ast.ForStatement) {
+	labels := b.takeLabels()
+	defer b.nest(false)()
+
+	if n.Initializer != nil {
+		switch init := n.Initializer.Initializer.(type) {
+		case *ast.VariableDeclaration:
+			// Let and const declarations are scoped to the loop.
+			if init.Token != token.Var {
+				var lexicals []*ast.Identifier
+				for _, d := range init.List {
+					lexicals = append(lexicals, boundIdentifiers(d.Target.Target)...)
+				}
+
+				b.enter(newLexicalScope(nil, lexicals))
+				defer b.leaveScope()
+			}
+
+			b.declaration(init)
+		case *ast.Expression:
+			b.value(init)
+		}
+	}
+
+	// Without a test, the loop only exits by jumping out of it.
+	hasTest := n.Test != nil && n.Test.Expr != nil
+	defer b.startLoop()()
+	defer b.nest(hasTest)()
+
+	head := b.block()
+	b.edge(b.current, head, FallThroughEdge)
+	b.current = head
+
+	if hasTest {
+		b.value(n.Test)
+	}
+
+	test := b.current
+	body := b.block()
+	update := b.block()
+	exit := b.block()
+	if hasTest {
+		b.edge(test, body, TrueEdge)
+		b.edge(test, exit, FalseEdge)
+	} else {
+		b.edge(test, body, FallThroughEdge)
+	}
+
+	// The update is written ahead of the body it runs after.
+	b.current = update
+	if n.Update != nil && n.Update.Expr != nil {
+		b.update = true
+		b.value(n.Update)
+		b.update = false
+	}
+
+	b.edge(b.current, head, BackEdge)
+
+	b.current = body
+	b.loop(labels, exit, update, n.Body)
+	b.edge(b.current, update, FallThroughEdge)
+	b.current = exit
+}
+
+// forEach lowers a for-in or for-of loop.
+// The source is evaluated once before the loop, and the loop binding is bound at the start of every iteration.
+func (b *cfgBuilder) forEach(into *ast.ForInto, source *ast.Expression, body *ast.Statement) {
+	labels := b.takeLabels()
+
+	// Let and const bindings of the loop are uninitialized while the source is evaluated.
+	if t, ok := into.Into.(*ast.VariableDeclaration); ok && t.Token != token.Var {
+		b.enter(newLexicalScope(nil, boundIdentifiers(t.List[0].Target.Target)))
+		b.value(source)
+		b.leaveScope()
+	} else {
+		b.value(source)
+	}
+
+	defer b.startLoop()()
+	defer b.nest(true)()
+
+	head := b.block()
+	b.edge(b.current, head, FallThroughEdge)
+
+	// The head checks if the source has another element.
+	start := b.block()
+	exit := b.block()
+	b.edge(head, start, TrueEdge)
+	b.edge(head, exit, FalseEdge)
+
+	b.current = start
+	switch t := into.Into.(type) {
+	case *ast.VariableDeclaration:
+		// Let and const bindings are scoped to each iteration, and are initialized as soon as it starts.
+		if t.Token != token.Var {
+			b.enter(newLexicalScope(identifierNames(boundIdentifiers(t.List[0].Target.Target)), nil))
+			defer b.leaveScope()
+		}
+
+		b.emit(&Item{Expr: source, Target: t.List[0].Target.Target, Kind: t.Token, Node: into})
+	case *ast.Expression:
+		b.emit(&Item{Expr: source, Target: t.Expr, Kind: token.Assign, Node: into})
+	}
+
+	b.loop(labels, exit, head, body)
+	b.edge(b.current, head, BackEdge)
+	b.current = exit
+}
+
+func (b *cfgBuilder) whileStatement(n *ast.WhileStatement) {
+	labels := b.takeLabels()
+	defer b.startLoop()()
+	defer b.nest(true)()
+
+	head := b.block()
+	b.edge(b.current, head, FallThroughEdge)
+	b.current = head
+	b.value(n.Test)
+
+	test := b.current
+	body := b.block()
+	exit := b.block()
+	b.edge(test, body, TrueEdge)
+	b.edge(test, exit, FalseEdge)
+
+	b.current = body
+	b.loop(labels, exit, head, n.Body)
+	b.edge(b.current, head, BackEdge)
+	b.current = exit
+}
+
+// doWhileStatement lowers a do-while loop, whose test leads back to the start of the body along a BackEdge when it passes.
+func (b *cfgBuilder) doWhileStatement(n *ast.DoWhileStatement) {
+	labels := b.takeLabels()
+	defer b.startLoop()()
+	defer b.nest(false)()
+
+	body := b.block()
+	b.edge(b.current, body, FallThroughEdge)
+	test := b.block()
+	exit := b.block()
+
+	b.current = body
+	b.loop(labels, exit, test, n.Body)
+	b.edge(b.current, test, FallThroughEdge)
+
+	b.current = test
+	b.value(n.Test)
+	b.edge(b.current, body, BackEdge)
+	b.edge(b.current, exit, FalseEdge)
+	b.current = exit
+}
+
+// switchStatement lowers a switch statement.
+// The test of every case is evaluated once the ones before it failed, and the default case is only taken once every test failed.
+func (b *cfgBuilder) switchStatement(n *ast.SwitchStatement) {
+	labels := b.takeLabels()
+
+	b.value(n.Discriminant)
+	defer b.nest(false)()
+
+	// The cases share a single block.
+	var lexicals []*ast.Identifier
+	for i := range n.Body {
+		lexicals = append(lexicals, lexicalDeclarations(n.Body[i].Consequent)...)
+	}
+
+	b.enter(newLexicalScope(nil, lexicals))
+	defer b.leaveScope()
+
+	exit := b.block()
+	b.targets = append(b.targets, &cfgTarget{labels: labels, breakable: true, breakTo: exit})
+
+	failed := b.current
+	var last, fallback *GraphNode
+	for i := range n.Body {
+		c := &n.Body[i]
+		body := b.block()
+		if c.Test != nil {
+			end := b.nest(true)
+			b.current = failed
+			b.next()
+			b.value(c.Test)
+			end()
+			b.edge(b.current, body, TrueEdge)
+
+			failed = b.block()
+			b.edge(b.current, failed, FalseEdge)
+		}
+
+		if i == n.Default {
+			fallback = body
+		}
+
+		// Cases without a break fall through into the next one.
+		if last != nil {
+			b.edge(last, body, FallThroughEdge)
+		}
+
+		end := b.nest(true)
+		b.current = body
+		b.statements(c.Consequent)
+		end()
+		last = b.current
+	}
+
+	if last != nil {
+		b.edge(last, exit, FallThroughEdge)
+	}
+
+	if fallback != nil {
+		b.edge(failed, fallback, FallThroughEdge)
+	} else {
+		b.edge(failed, exit, FallThroughEdge)
+	}
+
+	b.targets = b.targets[:len(b.targets)-1]
+	b.current = exit
+}
+
+// tryStatement lowers a try statement.
+// Every block of the try block may throw to the catch block, and every path leaving the statement runs the finally block on the way.
+// The finally block is built once for exceptions, once for every place jumps through it continue to, and once for the normal path,
+// so each path continues on after it with only the code that reached it.
+func (b *cfgBuilder) tryStatement(n *ast.TryStatement) {
+	outer := b.handler
+
+	var finally *cfgFinally
+	if n.Finally != nil {
+		finally = &cfgFinally{thrown: b.block()}
+		b.targets = append(b.targets, &cfgTarget{finally: finally})
+		b.handler = finally.thrown
+	}
+
+	var catch *GraphNode
+	if n.Catch != nil {
+		// The catch block is inside of the finally block's try statement.
+		catch = b.block()
+		b.handler = catch
+	}
+
+	// The try block starts out empty, so exceptions thrown by its first item carry the definitions reaching the statement.
+	b.next()
+	b.body(n.Body.List)
+	ends := []*GraphNode{b.current}
+
+	if n.Catch != nil {
+		b.handler = outer
+		if finally != nil {
+			b.handler = finally.thrown
+		}
+
+		b.current = catch
+		end := b.nest(true)
+		if n.Catch.Parameter != nil {
+			b.enter(newLexicalScope(identifierNames(boundIdentifiers(n.Catch.Parameter.Target)), nil))
+			b.emit(&Item{Target: n.Catch.Parameter.Target, Kind: token.Let, Node: n.Catch, param: true})
+		}
+
+		b.body(n.Catch.Body.List)
+		if n.Catch.Parameter != nil {
+			b.leaveScope()
+		}
+
+		end()
+		ends = append(ends, b.current)
+	}
+
+	b.handler = outer
+
+	if finally == nil {
+		b.current = b.block()
+		for _, end := range ends {
+			b.edge(end, b.current, FallThroughEdge)
+		}
+
+		return
+	}
+
+	b.targets = b.targets[:len(b.targets)-1]
+
+	// Exceptions are rethrown to the enclosing handler once the finally block completes.
+	if len(finally.thrown.Incoming) > 0 {
+		b.current = finally.thrown
+		b.body(n.Finally.List)
+		b.next()
+		b.throw(b.current)
+	}
+
+	// Jumps continue on to where they were going, possibly through the finally blocks of enclosing try statements.
+	// Jumps going to the same place share their copy of the finally block.
+	copies := make(map[cfgJump]*GraphNode)
+	var order []cfgJump
+	for _, j := range finally.jumps {
+		key := cfgJump{to: j.to, kind: j.kind, crossed: j.crossed}
+		entry, ok := copies[key]
+		if !ok {
+			entry = b.block()
+			copies[key] = entry
+			order = append(order, key)
+		}
+
+		b.edge(j.from, entry, j.kind)
+	}
+
+	for _, key := range order {
+		b.current = copies[key]
+		b.body(n.Finally.List)
+		b.leave(key.to, key.kind, key.crossed)
+	}
+
+	b.current = b.block()
+	for _, end := range ends {
+		b.edge(end, b.current, FallThroughEdge)
+	}
+
+	b.body(n.Finally.List)
+}
+
+// labelledStatement lowers a labelled statement.
+// Loops and switch statements take the label as their own, while breaking out of any other statement continues after it.
+func (b *cfgBuilder) labelledStatement(n *ast.LabelledStatement) {
+	b.labels = append(b.labels, n.Label.Name)
+
+	switch n.Statement.Stmt.(type) {
+	case *ast.ForStatement, *ast.ForInStatement, *ast.ForOfStatement, *ast.WhileStatement,
+		*ast.DoWhileStatement, *ast.SwitchStatement, *ast.LabelledStatement:
+		b.statement(n.Statement)
+		return
+	}
+
+	defer b.nest(false)()
+	exit := b.block()
+	b.targets = append(b.targets, &cfgTarget{labels: b.takeLabels(), breakTo: exit})
+	b.statement(n.Statement)
+	b.targets = b.targets[:len(b.targets)-1]
+
+	b.edge(b.current, exit, FallThroughEdge)
+	b.current = exit
+}
+
+// jump lowers a break or continue statement.
+// label is the label of the statement, or nil if it has none.
+// cont depicts if the statement is a continue statement.
+func (b *cfgBuilder) jump(label *ast.Identifier, cont bool) {
+	for i := len(b.targets) - 1; i >= 0; i-- {
+		t := b.targets[i]
+		if t.finally != nil {
+			continue
+		}
+
+		if label != nil {
+			if !containsLabel(t.labels, label.Name) {
+				continue
+			}
+		} else if (cont && !t.loop) || (!cont && !t.breakable) {
+			continue
+		}
+
+		if cont {
+			b.leave(t.continueTo, ContinueEdge, len(b.targets)-i-1)
+		} else {
+			b.leave(t.breakTo, BreakEdge, len(b.targets)-i-1)
+		}
+
+		return
+	}
+
+	// Jumps without a target are syntax errors, so nothing after them is reachable.
+	b.unreachable()
+}
+
+// containsLabel determines if a list of labels contains a label.
+func containsLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+
+	return false
+}
+
+// leave jumps from the current block to a node, running the finally blocks of every try statement it leaves on the way.
+// to is the node being jumped to, and kind is the kind of jump.
+// crossed is the number of innermost targets being left.
+func (b *cfgBuilder) leave(to *GraphNode, kind EdgeKind, crossed int) {
+	for i := len(b.targets) - 1; i >= len(b.targets)-crossed; i-- {
+		if f := b.targets[i].finally; f != nil {
+			// The jump continues once the innermost finally block it leaves has been built.
+			f.jumps = append(f.jumps, &cfgJump{from: b.current, to: to, kind: kind, crossed: i - (len(b.targets) - crossed)})
+			b.unreachable()
+			return
+		}
+	}
+
+	b.edge(b.current, to, kind)
+
+	// Nothing after a jump is reachable.
+	b.unreachable()
+}
+
+// unreachable continues after a jump into a new block that nothing reaches.
+func (b *cfgBuilder) unreachable() {
+	b.current = b.block()
+}
+
+// class lowers the definition of a class in the order it's evaluated.
+// The heritage and computed keys are evaluated first, then the constructor and methods are created,
+// and finally static fields and static blocks run in the order they're declared.
+// decl is the declaration of the class, or nil if it's a class expression.
+func (b *cfgBuilder) class(c *ast.ClassLiteral, decl *ast.ClassDeclaration) {
+	if c.SuperClass != nil {
+		b.value(c.SuperClass)
+	}
+
+	for i := range c.Body {
+		switch e := c.Body[i].Element.(type) {
+		case *ast.FieldDefinition:
+			if e.Computed {
+				b.value(e.Key)
+			}
+		case *ast.MethodDefinition:
+			if e.Computed {
+				b.value(e.Key)
+			}
+		}
+	}
+
+	// Class declarations bind their name in the enclosing block, while class expressions bind it inside of the class itself.
+	value := &ast.Expression{Expr: c}
+	switch {
+	case decl != nil && c.Name != nil:
+		b.emit(&Item{Expr: value, Target: c.Name, Kind: token.Class, Node: decl})
+	case c.Name != nil:
+		b.enter(newLexicalScope([]string{c.Name.Name}, nil))
+		defer b.leaveScope()
+		b.emit(&Item{Expr: value, Target: c.Name, Kind: token.Class, Node: c})
+	default:
+		b.emit(&Item{Expr: value})
+	}
+
+	defer b.nest(false)()
+
+	// The constructor and instance methods refer to the instance, while static elements refer to the class.
+	this, name := b.this, ""
+	if c.Name != nil {
+		name = c.Name.Name
+	}
+
+	defer func() {
+		b.this = this
+	}()
+
+	// The constructor is created along with the binding of the class.
+	b.this = "this"
+	b.constructor(c)
+
+	for i := range c.Body {
+		switch e := c.Body[i].Element.(type) {
+		case *ast.MethodDefinition:
+			if !isConstructor(e) {
+				b.this = "this"
+				if e.Static {
+					b.this = name
+				}
+
+				b.functionLiteral(e.Body, false)
+			}
+		}
+	}
+
+	b.this = name
+	for i := range c.Body {
+		switch e := c.Body[i].Element.(type) {
+		case *ast.FieldDefinition:
+			if e.Static {
+				b.field(e)
+			}
+		case *ast.MethodDefinition:
+			if !isConstructor(e) {
+				b.emit(&Item{Expr: &ast.Expression{Expr: e.Body}})
+			}
+		case *ast.ClassStaticBlock:
+			b.body(e.Block.List)
+		}
+	}
+}
+
+// constructor builds the graph of a class constructor, which initializes the instance fields before its body runs.
+func (b *cfgBuilder) constructor(c *ast.ClassLiteral) *FunctionGraph {
+	var ctor *ast.FunctionLiteral
+	for i := range c.Body {
+		if m, ok := c.Body[i].Element.(*ast.MethodDefinition); ok && isConstructor(m) {
+			ctor = m.Body
+		}
+	}
+
+	scope := functionScope(nil, nil, nil)
+	if ctor != nil {
+		scope = functionScope(nil, &ctor.ParameterList, ctor.Body.List)
+	}
+
+	scope.names["arguments"] = true
+
+	g := b.function(c, scope, func() {
+		var body ast.Statements
+		if ctor != nil {
+			body = ctor.Body.List
+			b.parameters(&ctor.ParameterList)
+		}
+
+		// The declarations hoisted to the top of the body are bound before the fields are initialized.
+		b.hoist(body)
+		for i := range c.Body {
+			if f, ok := c.Body[i].Element.(*ast.FieldDefinition); ok && !f.Static {
+				b.field(f)
+			}
+		}
+
+		b.lower(body)
+	})
+
+	if ctor != nil {
+		b.cfg.graphs[ctor] = g
+	}
+
+	return g
+}
+
+// field lowers a field definition, which defines a property of this.
+func (b *cfgBuilder) field(f *ast.FieldDefinition) {
+	if f.Initializer != nil && b.hasFlow(f.Initializer) {
+		b.value(f.Initializer)
+	}
+
+	b.emit(&Item{Expr: f.Initializer, Target: f.Key.Expr, Node: f})
+}
+
+// value lowers the evaluation of an expression, which is evaluated by the last item added.
+// Expressions that short-circuit are lowered into blocks of their own, and are then marked as lowered.
+func (b *cfgBuilder) value(e *ast.Expression) {
+	if e == nil || e.Expr == nil {
+		return
+	}
+
+	switch t := e.Expr.(type) {
+	case *ast.BinaryExpression:
+		if shortCircuits(t.Operator) {
+			b.logical(t.Operator, t.Left, t.Right)
+			break
+		}
+
+		ops := b.flatten(e)
+		b.emit(&Item{Expr: e, operands: ops})
+	case *ast.ConditionalExpression:
+		b.conditional(t)
+	case *ast.OptionalChain:
+		b.chains = append(b.chains, nil)
+		b.value(t.Base)
+
+		shorts := b.chains[len(b.chains)-1]
+		b.chains = b.chains[:len(b.chains)-1]
+
+		end := b.block()
+		b.edge(b.current, end, FallThroughEdge)
+		for _, s := range shorts {
+			b.edge(s, end, TrueEdge)
+		}
+
+		b.current = end
+	case *ast.Optional:
+		b.value(t.Expr)
+
+		// The rest of the chain is skipped if the value is null or undefined.
+		if len(b.chains) > 0 {
+			b.chains[len(b.chains)-1] = append(b.chains[len(b.chains)-1], b.current)
+			rest := b.block()
+			b.edge(b.current, rest, FalseEdge)
+			b.current = rest
+		}
+	case *ast.ClassLiteral:
+		b.class(t, nil)
+	default:
+		ops := b.flatten(e)
+		b.emit(&Item{Expr: e, operands: ops})
+	}
+
+	b.cfg.lowered[e.Expr] = true
+}
+
+// logical lowers an expression that only evaluates its right side depending on the value of its left side.
+// op is the operator, and left and right are its operands.
+func (b *cfgBuilder) logical(op token.Token, left *ast.Expression, right *ast.Expression) {
+	b.value(left)
+	test := b.current
+
+	rhs := b.block()
+	if op == token.LogicalOr {
+		b.edge(test, rhs, FalseEdge)
+	} else {
+		b.edge(test, rhs, TrueEdge)
+	}
+
+	b.current = rhs
+	done := b.nest(true)
+	b.value(right)
+	done()
+
+	end := b.block()
+	b.edge(b.current, end, FallThroughEdge)
+	if op == token.LogicalOr {
+		b.edge(test, end, TrueEdge)
+	} else {
+		b.edge(test, end, FalseEdge)
+	}
+
+	b.current = end
+}
+
+func (b *cfgBuilder) conditional(n *ast.ConditionalExpression) {
+	b.value(n.Test)
+	test := b.current
+
+	end := b.nest(true)
+	b.current = b.block()
+	b.edge(test, b.current, TrueEdge)
+	b.value(n.Consequent)
+	consequent := b.current
+	end()
+
+	end = b.nest(true)
+	b.current = b.block()
+	b.edge(test, b.current, FalseEdge)
+	b.value(n.Alternate)
+	alternate := b.current
+	end()
+
+	b.current = b.block()
+	b.edge(consequent, b.current, FallThroughEdge)
+	b.edge(alternate, b.current, FallThroughEdge)
+}
+
+// flatten lowers the operands of an expression that have to be evaluated ahead of it, so the expression can be evaluated by a single item.
+// Operands are evaluated in order, so every operand up to the last one that branches is lowered.
+// Callees are left to the call, unless they branch themselves.
+// The items evaluating every lowered operand are returned, as the expression evaluates them in between its own accesses.
+func (b *cfgBuilder) flatten(e *ast.Expression) map[ast.Expr][]*Item {
+	ops := operands(e.Expr)
+
+	last := -1
+	for i, op := range ops {
+		if b.hasFlow(op) {
+			last = i
+		}
+	}
+
+	items := make(map[ast.Expr][]*Item)
+	for i := 0; i <= last; i++ {
+		if i < last && isCallee(e.Expr, ops[i]) && !b.hasFlow(ops[i]) {
+			continue
+		}
+
+		var op []*Item
+		outer := b.operand
+		b.operand = &op
+		b.value(ops[i])
+		b.operand = outer
+
+		for _, item := range op {
+			item.operand = true
+		}
+
+		items[ops[i].Expr] = op
+	}
+
+	return items
+}
+
+// isCallee determines if an operand is the callee of a call, new expression or tagged template.
+func isCallee(e ast.Expr, op *ast.Expression) bool {
+	switch t := e.(type) {
+	case *ast.CallExpression:
+		return t.Callee == op
+	case *ast.NewExpression:
+		return t.Callee == op
+	case *ast.TemplateLiteral:
+		return t.Tag == op
+	}
+
+	return false
+}
+
+// operands returns the operands of an expression that are evaluated before it, in the order they're evaluated.
+// Assignment targets are left out, as they're defined rather than evaluated.
+func operands(e ast.Expr) []*ast.Expression {
+	var ops []*ast.Expression
+	add := func(exprs ...*ast.Expression) {
+		for _, op := range exprs {
+			if op != nil && op.Expr != nil {
+				ops = append(ops, op)
+			}
+		}
+	}
+
+	switch t := e.(type) {
+	case *ast.ArrayLiteral:
+		for i := range t.Value {
+			add(&t.Value[i])
+		}
+	case *ast.AssignExpression:
+		add(t.Right)
+	case *ast.AwaitExpression:
+		add(t.Argument)
+	case *ast.YieldExpression:
+		add(t.Argument)
+	case *ast.BinaryExpression:
+		add(t.Left, t.Right)
+	case *ast.CallExpression:
+		add(t.Callee)
+		for i := range t.ArgumentList {
+			add(&t.ArgumentList[i])
+		}
+	case *ast.NewExpression:
+		add(t.Callee)
+		for i := range t.ArgumentList {
+			add(&t.ArgumentList[i])
+		}
+	case *ast.MemberExpression:
+		add(t.Object)
+		if c, ok := t.Property.Prop.(*ast.ComputedProperty); ok {
+			add(c.Expr)
+		}
+	case *ast.PrivateDotExpression:
+		add(t.Left)
+	case *ast.ObjectLiteral:
+		for i := range t.Value {
+			switch p := t.Value[i].Prop.(type) {
+			case *ast.PropertyKeyed:
+				if p.Computed {
+					add(p.Key)
+				}
+				add(p.Value)
+			case *ast.SpreadElement:
+				add(p.Expression)
+			}
+		}
+	case *ast.SequenceExpression:
+		for i := range t.Sequence {
+			add(&t.Sequence[i])
+		}
+	case *ast.SpreadElement:
+		add(t.Expression)
+	case *ast.TemplateLiteral:
+		add(t.Tag)
+		for i := range t.Expressions {
+			add(&t.Expressions[i])
+		}
+	case *ast.UnaryExpression:
+		add(t.Operand)
+	}
+
+	return ops
+}
+
+// hasFlow determines if evaluating an expression branches, or runs a class definition, so it has to be lowered into blocks of its own.
+// Functions created by the expression are left out, as they have graphs of their own.
+func (b *cfgBuilder) hasFlow(e *ast.Expression) bool {
+	f := &flowFinder{cfg: b.cfg}
+	f.V = f
+	e.VisitWith(f)
+	return f.found
+}
+
+// flowFinder finds the first expression that branches, without entering functions or lowered expressions.
+type flowFinder struct {
+	ast.NoopVisitor
+	cfg   *CFG
+	found bool
+}
+
+func (f *flowFinder) VisitExpression(n *ast.Expression) {
+	if f.found || n.Expr == nil || f.cfg.lowered[n.Expr] {
+		return
+	}
+
+	n.VisitChildrenWith(f)
+}
+
+func (f *flowFinder) VisitBinaryExpression(n *ast.BinaryExpression) {
+	if shortCircuits(n.Operator) {
+		f.found = true
+		return
+	}
+
+	n.VisitChildrenWith(f)
+}
+
+func (f *flowFinder) VisitConditionalExpression(n *ast.ConditionalExpression) {
+	f.found = true
+}
+
+func (f *flowFinder) VisitOptionalChain(n *ast.OptionalChain) {
+	f.found = true
+}
+
+func (f *flowFinder) VisitOptional(n *ast.Optional) {
+	f.found = true
+}
+
+func (f *flowFinder) VisitClassLiteral(n *ast.ClassLiteral) {
+	f.found = true
+}
+
+func (f *flowFinder) VisitFunctionLiteral(n *ast.FunctionLiteral) {}
+
+func (f *flowFinder) VisitArrowFunctionLiteral(n *ast.ArrowFunctionLiteral) {}
+
+// parameters returns the parameters of the function a graph is built from, or nil if it has none.
+func parameters(g *FunctionGraph) *ast.ParameterList {
+	switch fn := g.Node.(type) {
+	case *ast.FunctionLiteral:
+		return &fn.ParameterList
+	case *ast.ArrowFunctionLiteral:
+		return &fn.ParameterList
+	case *ast.ClassLiteral:
+		for i := range fn.Body {
+			if m, ok := fn.Body[i].Element.(*ast.MethodDefinition); ok && isConstructor(m) {
+				return &m.Body.ParameterList
+			}
+		}
+	}
+
+	return nil
+}
+
+// isParameter determines if a declarator is one of the parameters of the function a graph is built from.
+func isParameter(g *FunctionGraph, d *ast.VariableDeclarator) bool {
+	if params := parameters(g); params != nil {
+		for i := range params.List {
+			if &params.List[i] == d {
+				return true
+			}
+		}
+	}
+
+	return false
 }
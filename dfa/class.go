@@ -0,0 +1,134 @@
+package dfa
+
+import (
+	"github.com/t14raptor/go-fast/ast"
+)
+
+// visitClass visits a class in the order its definition is evaluated.
+// The heritage and computed keys are evaluated first, then the constructor and methods are created,
+// and finally static fields and static blocks run in the order they're declared.
+// c is the class being visited.
+// declared depicts if the class is a declaration, which binds its name in the enclosing block.
+func (lv *DfaVisitor) visitClass(c *ast.ClassLiteral, declared bool) {
+	if c.SuperClass != nil {
+		lv.VisitExpression(c.SuperClass)
+	}
+
+	for i := range c.Body {
+		switch e := c.Body[i].Element.(type) {
+		case *ast.FieldDefinition:
+			if e.Computed {
+				lv.VisitExpression(e.Key)
+			}
+		case *ast.MethodDefinition:
+			if e.Computed {
+				lv.VisitExpression(e.Key)
+			}
+		}
+	}
+
+	name := ""
+	if c.Name != nil {
+		name = c.Name.Name
+	}
+
+	if declared && name != "" {
+		lv.Ctx.addValue(lv.Ctx.scopeStack[lv.Ctx.scopeDepth], name, &ast.Expression{Expr: c}, true, BlockScope, lv.Ctx.lexicalDepth())
+	}
+
+	classScope := NewScope(false, false)
+	lv.Ctx.pushScope(classScope)
+
+	// The name of a class expression is only bound inside of the class itself.
+	if !declared && name != "" {
+		lv.Ctx.addValue(classScope, name, &ast.Expression{Expr: c}, true, BlockScope, lv.Ctx.scopeDepth)
+	}
+
+	thisName, instance := lv.Ctx.thisName, lv.Ctx.instance
+	lv.Ctx.instance = lv.visitConstructor(c)
+
+	// Static elements run with this referring to the class, which can only be resolved through its name.
+	lv.Ctx.thisName = name
+
+	for i := range c.Body {
+		switch e := c.Body[i].Element.(type) {
+		case *ast.FieldDefinition:
+			if e.Static {
+				lv.VisitFieldDefinition(e)
+			}
+		case *ast.MethodDefinition:
+			if !isConstructor(e) {
+				lv.VisitMethodDefinition(e)
+			}
+		case *ast.ClassStaticBlock:
+			lv.VisitClassStaticBlock(e)
+		}
+	}
+
+	lv.Ctx.thisName, lv.Ctx.instance = thisName, instance
+
+	lv.Ctx.popScope()
+	lv.Ctx.mergeDown(lv.Ctx.scopeDepth+1, classScope, false)
+}
+
+// visitConstructor visits the constructor of a class in its own function scope, with the instance fields initialized before its body runs.
+// Classes without a constructor still initialize their instance fields when they're constructed.
+// c is the class being visited.
+// Returns a scope holding the properties of this once the constructor returns.
+func (lv *DfaVisitor) visitConstructor(c *ast.ClassLiteral) *Scope {
+	var ctor *ast.FunctionLiteral
+	for i := range c.Body {
+		if m, ok := c.Body[i].Element.(*ast.MethodDefinition); ok && isConstructor(m) {
+			ctor = m.Body
+		}
+	}
+
+	params := &ast.ParameterList{}
+	var body ast.Statements
+	if ctor != nil {
+		params = &ctor.ParameterList
+		body = ctor.Body.List
+	}
+
+	thisName := lv.Ctx.thisName
+	lv.Ctx.thisName = "this"
+
+	instance := NewScope(false, false)
+	lv.visitFunction(c, nil, params, body, functionEntry{created: lv.Ctx.defCount}, func() {
+		functionScope := lv.Ctx.scopeStack[lv.Ctx.scopeDepth]
+
+		// Every construction starts with a new object.
+		copyProperties(functionScope, instance, "this")
+
+		for i := range c.Body {
+			if f, ok := c.Body[i].Element.(*ast.FieldDefinition); ok && !f.Static {
+				lv.VisitFieldDefinition(f)
+			}
+		}
+
+		if ctor != nil {
+			lv.VisitBlockStatement(ctor.Body)
+		}
+
+		copyProperties(instance, lv.Ctx.scopeStack[lv.Ctx.scopeDepth], "this")
+	})
+
+	lv.Ctx.thisName = thisName
+	return instance
+}
+
+// isConstructor determines if a method definition is the constructor of its class.
+func isConstructor(m *ast.MethodDefinition) bool {
+	key, ok := m.Key.Expr.(*ast.StringLiteral)
+	return ok && !m.Static && !m.Computed && key.Value == "constructor"
+}
+
+// elementName returns the path segment of the property a class element defines.
+// Computed keys that aren't literals resolve to anyProperty.
+func elementName(key *ast.Expression) string {
+	if k, ok := key.Expr.(*ast.PrivateIdentifier); ok {
+		return "#" + k.Identifier.Name
+	}
+
+	return literalName(key.Expr)
+}
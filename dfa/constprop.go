@@ -0,0 +1,235 @@
+package dfa
+
+import (
+	"math"
+
+	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/token"
+)
+
+// Constant is the value of a variable according to constant propagation.
+type Constant struct {
+	// Known depicts if the variable holds Value on every path reaching the point.
+	Known bool
+	// Value is the value of a known variable, which is a float64, string or bool, or nil for null.
+	Value any
+}
+
+// Constants holds the value of every variable assigned on some path reaching a point.
+// Variables that aren't assigned on any path have no value, and can be assigned anything by a joining path.
+type Constants map[Variable]Constant
+
+// Value returns the value of a variable, or false if it isn't known to be constant.
+func (c Constants) Value(v Variable) (any, bool) {
+	return c[v].Value, c[v].Known
+}
+
+// ConstantPropagation is a forward analysis computing the variables holding the same value on every path reaching a point.
+// Values are folded from literals, constant variables and arithmetic on them, and any other value is unknown.
+// Variables assigned by the functions a graph creates may change whenever they're called, so they're never known in the graph.
+type ConstantPropagation struct {
+	cfg *CFG
+	// escaped holds the variables assigned by the functions every graph creates.
+	escaped map[*FunctionGraph]VarSet
+}
+
+// NewConstantPropagation creates the constant propagation analysis of the graphs of a control flow graph.
+func NewConstantPropagation(c *CFG) *ConstantPropagation {
+	return &ConstantPropagation{cfg: c, escaped: make(map[*FunctionGraph]VarSet)}
+}
+
+func (p *ConstantPropagation) Bottom() Constants {
+	return Constants{}
+}
+
+// Join keeps the variables holding the same value on both paths, and the ones assigned on a single path.
+func (p *ConstantPropagation) Join(a Constants, b Constants) Constants {
+	joined := make(Constants, len(a)+len(b))
+	for v, c := range a {
+		joined[v] = c
+	}
+
+	for v, c := range b {
+		if other, ok := joined[v]; ok && other != c {
+			c = Constant{}
+		}
+
+		joined[v] = c
+	}
+
+	return joined
+}
+
+func (p *ConstantPropagation) Equal(a Constants, b Constants) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for v, c := range a {
+		if other, ok := b[v]; !ok || other != c {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Entry returns no values, as the variables a function reads from outside of it may hold anything when it's called.
+func (p *ConstantPropagation) Entry(g *FunctionGraph) Constants {
+	return Constants{}
+}
+
+// Transfer applies the writes of every item. Items that directly assign or declare a single variable give it the value they evaluate to,
+// and every other write makes its variable unknown.
+func (p *ConstantPropagation) Transfer(n *GraphNode, in Constants) Constants {
+	escaped := p.escapedBy(n.Graph)
+	state := p.Join(in, nil)
+
+	for _, item := range n.Items {
+		id, value := assignedValue(item, n.Graph)
+
+		var c Constant
+		if id != nil && !escaped[item.Variable(id.Name)] {
+			c = p.eval(item, value, state)
+		}
+
+		for _, a := range p.cfg.Accesses(item) {
+			if a.Write && !a.Deferred && a.Direct() {
+				state[a.Var] = Constant{}
+			}
+		}
+
+		if id != nil {
+			state[item.Variable(id.Name)] = c
+		}
+	}
+
+	return state
+}
+
+// assignedValue returns the variable an item directly assigns or declares, and the expression giving its value.
+// Items that don't assign a single variable with a plain assignment return nil.
+func assignedValue(item *Item, g *FunctionGraph) (*ast.Identifier, *ast.Expression) {
+	switch n := item.Node.(type) {
+	case nil:
+		if item.Expr == nil {
+			return nil, nil
+		}
+
+		if assign, ok := item.Expr.Expr.(*ast.AssignExpression); ok && assign.Operator == token.Assign {
+			if id, ok := assign.Left.Expr.(*ast.Identifier); ok {
+				return id, assign.Right
+			}
+		}
+	case *ast.VariableDeclarator:
+		// Declarations without an initializer leave the variable undefined, which isn't tracked,
+		// and parameters are only initialized with their default value if they aren't passed.
+		if id, ok := item.Target.(*ast.Identifier); ok && item.Expr != nil && !isParameter(g, n) {
+			return id, item.Expr
+		}
+	}
+
+	return nil, nil
+}
+
+// eval folds the value of an expression from the values reaching an item.
+// Lowered sub-expressions are evaluated by items of their own, so their value is unknown.
+func (p *ConstantPropagation) eval(item *Item, e *ast.Expression, state Constants) Constant {
+	if e == nil || e.Expr == nil || p.cfg.lowered[e.Expr] && e != item.Expr {
+		return Constant{}
+	}
+
+	switch t := e.Expr.(type) {
+	case *ast.NumberLiteral:
+		return Constant{Known: true, Value: t.Value}
+	case *ast.StringLiteral:
+		return Constant{Known: true, Value: t.Value}
+	case *ast.BooleanLiteral:
+		return Constant{Known: true, Value: t.Value}
+	case *ast.NullLiteral:
+		return Constant{Known: true}
+	case *ast.Identifier:
+		return state[item.Variable(t.Name)]
+	case *ast.UnaryExpression:
+		c := p.eval(item, t.Operand, state)
+		switch v := c.Value.(type) {
+		case float64:
+			if t.Operator == token.Minus {
+				return Constant{Known: c.Known, Value: -v}
+			}
+		case bool:
+			if t.Operator == token.Not {
+				return Constant{Known: c.Known, Value: !v}
+			}
+		}
+	case *ast.BinaryExpression:
+		left, right := p.eval(item, t.Left, state), p.eval(item, t.Right, state)
+		if !left.Known || !right.Known {
+			return Constant{}
+		}
+
+		return foldBinary(t.Operator, left.Value, right.Value)
+	}
+
+	return Constant{}
+}
+
+// foldBinary computes the value of a binary operator on two known values.
+// Only arithmetic on numbers and concatenation of strings are folded.
+// NaN isn't equal to itself, so it's left unknown for facts to ever stop changing.
+func foldBinary(op token.Token, left any, right any) Constant {
+	if l, ok := left.(string); ok {
+		if r, ok := right.(string); ok && op == token.Plus {
+			return Constant{Known: true, Value: l + r}
+		}
+
+		return Constant{}
+	}
+
+	l, ok := left.(float64)
+	r, rok := right.(float64)
+	if !ok || !rok {
+		return Constant{}
+	}
+
+	var v float64
+	switch op {
+	case token.Plus:
+		v = l + r
+	case token.Minus:
+		v = l - r
+	case token.Multiply:
+		v = l * r
+	case token.Slash:
+		v = l / r
+	default:
+		return Constant{}
+	}
+
+	if math.IsNaN(v) {
+		return Constant{}
+	}
+
+	return Constant{Known: true, Value: v}
+}
+
+// escapedBy returns the variables assigned by the functions a graph creates.
+func (p *ConstantPropagation) escapedBy(g *FunctionGraph) VarSet {
+	if escaped, ok := p.escaped[g]; ok {
+		return escaped
+	}
+
+	escaped := VarSet{}
+	for _, n := range g.Nodes {
+		for _, item := range n.Items {
+			for _, a := range p.cfg.Accesses(item) {
+				if a.Deferred && a.Write && a.Direct() {
+					escaped[a.Var] = true
+				}
+			}
+		}
+	}
+
+	p.escaped[g] = escaped
+	return escaped
+}
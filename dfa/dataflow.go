@@ -0,0 +1,190 @@
+package dfa
+
+// Lattice is the domain of the facts a dataflow analysis computes.
+// Facts are treated as values, so Join and transfer functions never modify the facts they're given.
+type Lattice[F any] interface {
+	// Bottom returns the fact that holds on paths that haven't been reached yet. Joining it with any fact results in that fact.
+	Bottom() F
+	// Join returns the fact holding where two paths meet.
+	Join(a F, b F) F
+	// Equal determines if two facts are the same, which is how the solver knows a graph is solved.
+	Equal(a F, b F) bool
+}
+
+// ForwardTransfer computes the facts of an analysis that flows from the entry of a graph to its exit.
+type ForwardTransfer[F any] interface {
+	// Entry returns the fact holding when the function starts.
+	Entry(g *FunctionGraph) F
+	// Transfer returns the fact holding after the items of a node, given the fact holding before them.
+	Transfer(n *GraphNode, in F) F
+}
+
+// BackwardTransfer computes the facts of an analysis that flows from the exit of a graph to its entry.
+type BackwardTransfer[F any] interface {
+	// Exit returns the fact holding when the function returns or throws.
+	Exit(g *FunctionGraph) F
+	// Transfer returns the fact holding before the items of a node, given the fact holding after them.
+	Transfer(n *GraphNode, out F) F
+}
+
+// EdgeTransfer is implemented by analyses whose facts change along the edges of a graph, such as when an edge leaves a scope.
+// Analyses that don't implement it pass facts along edges unchanged.
+type EdgeTransfer[F any] interface {
+	// TransferEdge returns the fact reaching the end of an edge, given the fact at its start.
+	TransferEdge(e *Edge, f F) F
+}
+
+// ForwardAnalysis is a dataflow analysis that flows from the entry of a graph to its exit.
+type ForwardAnalysis[F any] interface {
+	Lattice[F]
+	ForwardTransfer[F]
+}
+
+// BackwardAnalysis is a dataflow analysis that flows from the exit of a graph to its entry.
+type BackwardAnalysis[F any] interface {
+	Lattice[F]
+	BackwardTransfer[F]
+}
+
+// Facts holds the facts a dataflow analysis computed for the nodes of a graph.
+type Facts[F any] struct {
+	// In holds the fact holding before the items of every node.
+	In map[*GraphNode]F
+	// Out holds the fact holding after the items of every node.
+	Out map[*GraphNode]F
+}
+
+// SolveForward solves a forward analysis over a graph with a worklist, until the facts of every node stop changing.
+// Nodes that can't be reached from the entry of the graph have no facts.
+// Exceptions may be thrown before any item of a node completes, so exception edges carry the fact holding before the node.
+func SolveForward[F any](g *FunctionGraph, a ForwardAnalysis[F]) *Facts[F] {
+	facts := &Facts[F]{In: make(map[*GraphNode]F), Out: make(map[*GraphNode]F)}
+	edges, _ := a.(EdgeTransfer[F])
+
+	facts.In[g.Entry] = a.Entry(g)
+	facts.Out[g.Entry] = a.Transfer(g.Entry, facts.In[g.Entry])
+
+	w := newNodeQueue(g.Entry.Children)
+	for n, ok := w.pop(); ok; n, ok = w.pop() {
+		in, reached := a.Bottom(), false
+		for _, e := range n.Incoming {
+			f, ok := facts.Out[e.From]
+			if e.Kind == ExceptionEdge {
+				f = facts.In[e.From]
+			}
+
+			if !ok {
+				continue
+			}
+
+			if edges != nil {
+				f = edges.TransferEdge(e, f)
+			}
+
+			reached = true
+			in = a.Join(in, f)
+		}
+
+		if old, ok := facts.In[n]; !reached || ok && a.Equal(old, in) {
+			continue
+		}
+
+		facts.In[n] = in
+		facts.Out[n] = a.Transfer(n, in)
+		w.push(n.Children...)
+	}
+
+	return facts
+}
+
+// SolveBackward solves a backward analysis over a graph with a worklist, until the facts of every node stop changing.
+// Every node has facts, including the ones that never reach the exit of the graph, such as the body of an infinite loop.
+// Exceptions may be thrown before any item of a node completes, so the fact at the handler also holds before the node.
+func SolveBackward[F any](g *FunctionGraph, a BackwardAnalysis[F]) *Facts[F] {
+	facts := &Facts[F]{In: make(map[*GraphNode]F), Out: make(map[*GraphNode]F)}
+	edges, _ := a.(EdgeTransfer[F])
+
+	nodes := make([]*GraphNode, 0, len(g.Nodes))
+	for i := len(g.Nodes) - 1; i >= 0; i-- {
+		nodes = append(nodes, g.Nodes[i])
+	}
+
+	w := newNodeQueue(nodes)
+	for n, ok := w.pop(); ok; n, ok = w.pop() {
+		out := a.Bottom()
+		if n == g.Exit {
+			out = a.Exit(g)
+		}
+
+		var thrown []F
+		for _, e := range n.Edges {
+			f, ok := facts.In[e.To]
+			if !ok {
+				continue
+			}
+
+			if edges != nil {
+				f = edges.TransferEdge(e, f)
+			}
+
+			if e.Kind == ExceptionEdge {
+				thrown = append(thrown, f)
+				continue
+			}
+
+			out = a.Join(out, f)
+		}
+
+		in := a.Transfer(n, out)
+		for _, f := range thrown {
+			in = a.Join(in, f)
+		}
+
+		old, ok := facts.In[n]
+		facts.Out[n] = out
+		if ok && a.Equal(old, in) {
+			continue
+		}
+
+		facts.In[n] = in
+		for _, e := range n.Incoming {
+			w.push(e.From)
+		}
+	}
+
+	return facts
+}
+
+// nodeQueue is a queue of nodes waiting to be solved, holding every node at most once.
+type nodeQueue struct {
+	nodes  []*GraphNode
+	queued map[*GraphNode]bool
+}
+
+func newNodeQueue(nodes []*GraphNode) *nodeQueue {
+	q := &nodeQueue{queued: make(map[*GraphNode]bool)}
+	q.push(nodes...)
+	return q
+}
+
+// push adds every node that isn't already waiting to the end of the queue.
+func (q *nodeQueue) push(nodes ...*GraphNode) {
+	for _, n := range nodes {
+		if !q.queued[n] {
+			q.queued[n] = true
+			q.nodes = append(q.nodes, n)
+		}
+	}
+}
+
+// pop removes the node at the front of the queue, or returns false if it's empty.
+func (q *nodeQueue) pop() (*GraphNode, bool) {
+	if len(q.nodes) == 0 {
+		return nil, false
+	}
+
+	n := q.nodes[0]
+	q.nodes = q.nodes[1:]
+	q.queued[n] = false
+	return n, true
+}
@@ -0,0 +1,130 @@
+package dfa
+
+import "github.com/t14raptor/go-fast/ast"
+
+// ECMAScriptGlobals holds the globals every ECMAScript environment provides.
+var ECMAScriptGlobals = []string{
+	"globalThis", "Infinity", "NaN", "undefined",
+	"eval", "isFinite", "isNaN", "parseFloat", "parseInt",
+	"decodeURI", "decodeURIComponent", "encodeURI", "encodeURIComponent", "escape", "unescape",
+	"AggregateError", "Array", "ArrayBuffer", "Atomics", "BigInt", "BigInt64Array", "BigUint64Array",
+	"Boolean", "DataView", "Date", "Error", "EvalError", "FinalizationRegistry", "Float32Array", "Float64Array",
+	"Function", "Int8Array", "Int16Array", "Int32Array", "Intl", "JSON", "Map", "Math", "Number", "Object",
+	"Promise", "Proxy", "RangeError", "ReferenceError", "Reflect", "RegExp", "Set", "SharedArrayBuffer",
+	"String", "Symbol", "SyntaxError", "TypeError", "Uint8Array", "Uint8ClampedArray", "Uint16Array",
+	"Uint32Array", "URIError", "WeakMap", "WeakRef", "WeakSet",
+}
+
+// BrowserGlobals holds the globals commonly provided by web browsers.
+var BrowserGlobals = []string{
+	"window", "self", "document", "navigator", "location", "history", "screen", "frames", "parent", "top",
+	"console", "alert", "confirm", "prompt", "fetch", "localStorage", "sessionStorage", "indexedDB", "crypto",
+	"performance", "setTimeout", "clearTimeout", "setInterval", "clearInterval", "queueMicrotask",
+	"requestAnimationFrame", "cancelAnimationFrame", "structuredClone", "atob", "btoa",
+	"addEventListener", "removeEventListener", "dispatchEvent",
+	"Event", "EventTarget", "CustomEvent", "Node", "Element", "HTMLElement", "Image", "Blob", "File", "FileReader",
+	"FormData", "Headers", "Request", "Response", "URL", "URLSearchParams", "WebSocket", "Worker", "XMLHttpRequest",
+	"AbortController", "MutationObserver", "TextEncoder", "TextDecoder",
+}
+
+// NodeGlobals holds the globals provided by Node.js, including the ones scoped to a CommonJS module.
+var NodeGlobals = []string{
+	"global", "process", "console", "Buffer",
+	"require", "module", "exports", "__dirname", "__filename",
+	"setTimeout", "clearTimeout", "setInterval", "clearInterval", "setImmediate", "clearImmediate",
+	"queueMicrotask", "structuredClone", "fetch", "atob", "btoa",
+	"URL", "URLSearchParams", "AbortController", "TextEncoder", "TextDecoder", "Event", "EventTarget",
+}
+
+// AddGlobals adds names to the globals provided by the environment the analyzed code runs in.
+// Uses of a global that isn't redefined by the code resolve to its environment definition.
+func (r *rdaContext) AddGlobals(names ...string) {
+	if r.Globals == nil {
+		r.Globals = make(map[string]bool)
+	}
+
+	for _, name := range names {
+		r.Globals[name] = true
+	}
+}
+
+// Ignore adds names that never produce a UseDef, such as the functions a harness injects into the analyzed code.
+func (r *rdaContext) Ignore(names ...string) {
+	if r.Ignored == nil {
+		r.Ignored = make(map[string]bool)
+	}
+
+	for _, name := range names {
+		r.Ignored[name] = true
+	}
+}
+
+// envDef returns the definition of a global provided by the environment.
+func (r *rdaContext) envDef(name string) *ScopeDef {
+	if def, ok := r.envDefs[name]; ok {
+		return def
+	}
+
+	def := &ScopeDef{
+		Id:          name,
+		Typ:         GlobalScope,
+		Count:       -1,
+		Environment: true,
+	}
+
+	r.envDefs[name] = def
+	return def
+}
+
+// defineGlobals defines every global the program refers to at the top of the global scope, as if they were defined before it runs.
+// Globals the program never refers to are left out, so they aren't carried through every scope.
+func (r *rdaContext) defineGlobals(a *ast.Program) {
+	if len(r.Globals) == 0 {
+		return
+	}
+
+	n := newNameVisitor()
+	a.VisitWith(n)
+
+	globalScope := r.scopeStack[0]
+	for name := range n.names {
+		if r.Globals[name] {
+			globalScope.Definitions[name] = []*ScopeDef{r.envDef(name)}
+		}
+	}
+}
+
+// markUnresolved marks every usage that no definition reaches, as it refers to a variable that's neither declared nor a known global.
+// Properties are left out, as an unresolved object is already marked by the usage of the object itself.
+func (r *rdaContext) markUnresolved() {
+	for _, ud := range r.UseDefs {
+		if ud.TDZ || len(ud.Path) > 0 {
+			continue
+		}
+
+		ud.Unresolved = true
+		for _, def := range ud.Definitions {
+			if def != nil {
+				ud.Unresolved = false
+				break
+			}
+		}
+	}
+}
+
+// nameVisitor collects the name of every identifier in a program.
+type nameVisitor struct {
+	ast.NoopVisitor
+	names map[string]bool
+}
+
+// newNameVisitor creates a new nameVisitor.
+func newNameVisitor() *nameVisitor {
+	n := &nameVisitor{names: make(map[string]bool)}
+	n.V = n
+	return n
+}
+
+func (n *nameVisitor) VisitIdentifier(id *ast.Identifier) {
+	n.names[id.Name] = true
+}
@@ -0,0 +1,67 @@
+package dfa
+
+import "github.com/t14raptor/go-fast/ast"
+
+// hoistVisitor collects the var declarations of a function body, which are hoisted to the top of its function scope.
+// Nested functions are skipped, as their declarations are hoisted into their own function scope.
+type hoistVisitor struct {
+	ast.NoopVisitor
+	// vars holds the names declared with var, in the order they're declared.
+	vars []string
+	// functions holds the names declared by function declarations, which are bound in the function scope as well.
+	functions []string
+}
+
+// newHoistVisitor creates a new hoistVisitor.
+func newHoistVisitor() *hoistVisitor {
+	h := &hoistVisitor{}
+	h.V = h
+	return h
+}
+
+func (h *hoistVisitor) VisitVariableDeclaration(n *ast.VariableDeclaration) {
+	if n.Token.String() != "var" {
+		return
+	}
+
+	for _, d := range n.List {
+		for _, id := range boundIdentifiers(d.Target.Target) {
+			h.vars = append(h.vars, id.Name)
+		}
+	}
+}
+
+func (h *hoistVisitor) VisitFunctionDeclaration(n *ast.FunctionDeclaration) {
+	if n.Function.Name != nil {
+		h.functions = append(h.functions, n.Function.Name.Name)
+	}
+}
+
+func (h *hoistVisitor) VisitFunctionLiteral(n *ast.FunctionLiteral) {}
+
+func (h *hoistVisitor) VisitArrowFunctionLiteral(n *ast.ArrowFunctionLiteral) {}
+
+func (h *hoistVisitor) VisitClassStaticBlock(n *ast.ClassStaticBlock) {}
+
+// lexicalDeclarations returns the identifiers declared with let, const and class by a statement list, in the order they're declared.
+func lexicalDeclarations(stmts ast.Statements) []*ast.Identifier {
+	ids := []*ast.Identifier{}
+	for i := range stmts {
+		switch s := stmts[i].Stmt.(type) {
+		case *ast.VariableDeclaration:
+			if s.Token.String() == "var" {
+				continue
+			}
+
+			for _, d := range s.List {
+				ids = append(ids, boundIdentifiers(d.Target.Target)...)
+			}
+		case *ast.ClassDeclaration:
+			if s.Class.Name != nil {
+				ids = append(ids, s.Class.Name)
+			}
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,97 @@
+package dfa
+
+// VarSet is a set of variables.
+type VarSet map[Variable]bool
+
+// Liveness is a backward analysis computing the variables that are live at every point of a graph,
+// whose value may still be read before it's written again.
+// Variables read by the functions a graph creates are live everywhere in the graph, as the functions may be called at any point.
+type Liveness struct {
+	cfg *CFG
+	// captured holds the variables read by the functions every graph creates.
+	captured map[*FunctionGraph]VarSet
+}
+
+// NewLiveness creates the liveness analysis of the graphs of a control flow graph.
+func NewLiveness(c *CFG) *Liveness {
+	return &Liveness{cfg: c, captured: make(map[*FunctionGraph]VarSet)}
+}
+
+func (l *Liveness) Bottom() VarSet {
+	return VarSet{}
+}
+
+func (l *Liveness) Join(a VarSet, b VarSet) VarSet {
+	joined := make(VarSet, len(a)+len(b))
+	for v := range a {
+		joined[v] = true
+	}
+
+	for v := range b {
+		joined[v] = true
+	}
+
+	return joined
+}
+
+func (l *Liveness) Equal(a VarSet, b VarSet) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for v := range a {
+		if !b[v] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Exit returns the variables read by the functions the graph creates, which may still be called once it returns.
+func (l *Liveness) Exit(g *FunctionGraph) VarSet {
+	return l.Join(l.capturedBy(g), nil)
+}
+
+// Transfer walks the accesses of every item backwards, where a read makes a variable live and a write ends its life.
+func (l *Liveness) Transfer(n *GraphNode, out VarSet) VarSet {
+	captured := l.capturedBy(n.Graph)
+	live := l.Join(out, nil)
+
+	for i := len(n.Items) - 1; i >= 0; i-- {
+		accesses := l.cfg.Accesses(n.Items[i])
+		for j := len(accesses) - 1; j >= 0; j-- {
+			a := accesses[j]
+			switch {
+			case !a.Direct():
+			case !a.Write:
+				live[a.Var] = true
+			case !a.Deferred && !captured[a.Var]:
+				delete(live, a.Var)
+			}
+		}
+	}
+
+	return live
+}
+
+// capturedBy returns the variables read by the functions a graph creates.
+func (l *Liveness) capturedBy(g *FunctionGraph) VarSet {
+	if captured, ok := l.captured[g]; ok {
+		return captured
+	}
+
+	captured := VarSet{}
+	for _, n := range g.Nodes {
+		for _, item := range n.Items {
+			for _, a := range l.cfg.Accesses(item) {
+				if a.Deferred && !a.Write && a.Direct() {
+					captured[a.Var] = true
+				}
+			}
+		}
+	}
+
+	l.captured[g] = captured
+	return captured
+}
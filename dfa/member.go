@@ -0,0 +1,405 @@
+package dfa
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/t14raptor/go-fast/ast"
+)
+
+// anyProperty is the path segment of a computed property whose key can't be resolved statically, which may be any property.
+const anyProperty = "[]"
+
+// accessPath resolves a member expression to the binding it's based on and the chain of property names leading to it.
+// Computed keys that aren't literals resolve to anyProperty, and private names keep their "#" prefix.
+// e is the expression being resolved, and thisName is the name of the binding this refers to, or an empty string if it can't be resolved.
+// ok denotes if the expression is based on an identifier or a resolvable this, as properties of anything else have no binding to belong to.
+func accessPath(e ast.Expr, thisName string) (base *ast.Identifier, path []string, ok bool) {
+	switch t := e.(type) {
+	case *ast.Identifier:
+		return t, nil, true
+	case *ast.ThisExpression:
+		if thisName == "" {
+			return nil, nil, false
+		}
+
+		return &ast.Identifier{Idx: t.Idx, Name: thisName}, nil, true
+	case *ast.MemberExpression:
+		base, path, ok = accessPath(t.Object.Expr, thisName)
+		if !ok {
+			return nil, nil, false
+		}
+
+		return base, append(path, propertyName(t.Property)), true
+	case *ast.PrivateDotExpression:
+		base, path, ok = accessPath(t.Left.Expr, thisName)
+		if !ok {
+			return nil, nil, false
+		}
+
+		return base, append(path, "#"+t.Identifier.Identifier.Name), true
+	}
+
+	return nil, nil, false
+}
+
+// propertyName returns the name of the property accessed by a member expression, or anyProperty if it's computed at runtime.
+// p is the property of the member expression.
+func propertyName(p *ast.MemberProperty) string {
+	switch prop := p.Prop.(type) {
+	case *ast.Identifier:
+		return prop.Name
+	case *ast.ComputedProperty:
+		return literalName(prop.Expr.Expr)
+	}
+
+	return anyProperty
+}
+
+// literalName returns the path segment of a property keyed by a string or number literal, or anyProperty for any other key.
+// Numbers are keyed by the string they convert to, so o[0] and o["0"] are the same property.
+func literalName(key ast.Expr) string {
+	switch k := key.(type) {
+	case *ast.StringLiteral:
+		return staticName(k.Value)
+	case *ast.NumberLiteral:
+		return staticName(numberString(k.Value))
+	}
+
+	return anyProperty
+}
+
+// numberString converts a number to a string the way JavaScript does,
+// which uses the shortest digits identifying it and switches to exponent form for very large and very small numbers.
+func numberString(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "Infinity"
+	case math.IsInf(v, -1):
+		return "-Infinity"
+	case v == 0:
+		return "0"
+	case v < 0:
+		return "-" + numberString(-v)
+	}
+
+	mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(v, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	e, _ := strconv.Atoi(exponent)
+
+	// n is the position of the decimal point relative to the digits.
+	n, k := e+1, len(digits)
+	switch {
+	case k <= n && n <= 21:
+		return digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		return digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		return "0." + strings.Repeat("0", -n) + digits
+	}
+
+	sign := "+"
+	if e < 0 {
+		sign, e = "-", -e
+	}
+
+	if k > 1 {
+		mantissa = digits[:1] + "." + digits[1:]
+	}
+
+	return mantissa + "e" + sign + strconv.Itoa(e)
+}
+
+// staticName returns the path segment of a property named by a string.
+// Names that can't be told apart from the separators of a path key are treated as any property.
+func staticName(name string) string {
+	if name == "" || strings.Contains(name, ".") || strings.Contains(name, anyProperty) {
+		return anyProperty
+	}
+
+	return name
+}
+
+// pathKey returns the key the definitions of an access path are stored under in a scope.
+// base is the name of the binding, and path is the chain of property names.
+func pathKey(base string, path []string) string {
+	var b strings.Builder
+	b.WriteString(base)
+
+	for _, p := range path {
+		if p == anyProperty {
+			b.WriteString(anyProperty)
+			continue
+		}
+
+		b.WriteByte('.')
+		b.WriteString(p)
+	}
+
+	return b.String()
+}
+
+// splitPath splits the key of an access path back into the name of its binding and its chain of property names.
+func splitPath(key string) (base string, path []string) {
+	end := strings.IndexAny(key, ".[")
+	if end < 0 {
+		return key, nil
+	}
+
+	base, key = key[:end], key[end:]
+	for key != "" {
+		if strings.HasPrefix(key, anyProperty) {
+			path = append(path, anyProperty)
+			key = key[len(anyProperty):]
+			continue
+		}
+
+		// Skip the separator of a static property name.
+		key = key[1:]
+
+		end := strings.IndexAny(key, ".[")
+		if end < 0 {
+			end = len(key)
+		}
+
+		path = append(path, key[:end])
+		key = key[end:]
+	}
+
+	return base, path
+}
+
+// mayAlias determines if two chains of property names may refer to the same property.
+func mayAlias(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] && a[i] != anyProperty && b[i] != anyProperty {
+			return false
+		}
+	}
+
+	return true
+}
+
+// killProperties replaces the definitions of every property of an access path, as they belong to the value being replaced.
+// Killed properties are Undefined, which resolves to the definitions of the new value when they're read.
+// key is the access path being defined.
+// overwrite denotes if the new value replaces the previous value, or may only replace it.
+func (s *Scope) killProperties(key string, overwrite bool) {
+	for id := range s.Definitions {
+		if !strings.HasPrefix(id, key+".") && !strings.HasPrefix(id, key+anyProperty) {
+			continue
+		}
+
+		if overwrite {
+			s.Definitions[id] = []*ScopeDef{Undefined}
+		} else if !s.HasDef(id, Undefined) {
+			s.Definitions[id] = append(s.Definitions[id], Undefined)
+		}
+	}
+}
+
+// addProperties defines every property initialized by an object literal as a property of an access path.
+// s is the scope the access path is defined in, and key is the access path the literal is assigned to.
+// lit is the object literal.
+// count, overwrite, typ and depth are the same as the definition of the access path.
+func (r *rdaContext) addProperties(count *int64, s *Scope, key string, lit *ast.ObjectLiteral, overwrite bool, typ ScopeDefType, depth int) {
+	for i := range lit.Value {
+		switch p := lit.Value[i].Prop.(type) {
+		case *ast.PropertyShort:
+			r.addValueAt(count, s, pathKey(key, []string{p.Name.Name}), &ast.Expression{Expr: p.Name}, overwrite, typ, depth)
+		case *ast.PropertyKeyed:
+			// Accessors don't hold the value they're defined with.
+			if p.Kind == ast.PropertyKindGet || p.Kind == ast.PropertyKindSet {
+				continue
+			}
+
+			name := literalName(p.Key.Expr)
+
+			// A property with an unknown key may not replace the properties it's defined next to.
+			r.addValueAt(count, s, pathKey(key, []string{name}), p.Value, overwrite && name != anyProperty, typ, depth)
+		}
+	}
+}
+
+// pathDefs returns the definitions that may reach a read of an access path from a scope.
+// Properties that haven't been defined take their value from the object they belong to, so they resolve to its definitions.
+// base is the name of the binding, and path is the chain of property names.
+func pathDefs(currentScope *Scope, base string, path []string) []*ScopeDef {
+	var parent []*ScopeDef
+	if len(path) == 1 {
+		parent = currentScope.Definitions[base]
+	} else {
+		parent = pathDefs(currentScope, base, path[:len(path)-1])
+	}
+
+	// Computed properties only may have defined the path, so it still takes its value from the object unless it was defined itself.
+	defs := []*ScopeDef{}
+	inherited := true
+	for key, list := range currentScope.Definitions {
+		b, p := splitPath(key)
+		if b != base || !mayAlias(p, path) {
+			continue
+		}
+
+		exact := key == pathKey(base, path)
+		if exact {
+			inherited = false
+		}
+
+		for _, def := range list {
+			if def == Undefined {
+				if exact {
+					defs = appendDefs(defs, parent)
+				}
+				continue
+			}
+
+			defs = appendDefs(defs, []*ScopeDef{def})
+		}
+	}
+
+	if inherited {
+		defs = appendDefs(defs, parent)
+	}
+
+	// Definitions are gathered from every aliasing path, so order them the way they were made.
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Count < defs[j].Count
+	})
+
+	return defs
+}
+
+// propertyDefs returns the definitions of every property under an access path in a scope, in the order they were made.
+// key is the access path.
+func propertyDefs(currentScope *Scope, key string) []*ScopeDef {
+	defs := []*ScopeDef{}
+	for id, list := range currentScope.Definitions {
+		if !strings.HasPrefix(id, key+".") && !strings.HasPrefix(id, key+anyProperty) {
+			continue
+		}
+
+		for _, def := range list {
+			if def != Undefined {
+				defs = appendDefs(defs, []*ScopeDef{def})
+			}
+		}
+	}
+
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Count < defs[j].Count
+	})
+
+	return defs
+}
+
+// readsWhole determines if a role reads the whole value of a variable, which may read any of its properties.
+func readsWhole(role Role) bool {
+	return role == ReadRole || role == CallRole
+}
+
+// appendDefs appends every definition that isn't already in the list.
+// Temporal dead zones are skipped, as they're reported by the use of the binding itself.
+func appendDefs(list []*ScopeDef, defs []*ScopeDef) []*ScopeDef {
+	for _, def := range defs {
+		if def != nil && !def.TDZ && !containsDef(list, def) {
+			list = append(list, def)
+		}
+	}
+
+	return list
+}
+
+// visitObject visits the object of a member expression and its computed key, which are evaluated before the property is accessed.
+// e is the member or private member expression.
+func (lv *DfaVisitor) visitObject(e ast.Expr) {
+	switch t := e.(type) {
+	case *ast.MemberExpression:
+		lv.visitReference(t.Object, ObjectRole)
+		if c, ok := t.Property.Prop.(*ast.ComputedProperty); ok {
+			lv.VisitExpression(c.Expr)
+		}
+	case *ast.PrivateDotExpression:
+		lv.visitReference(t.Left, ObjectRole)
+	}
+}
+
+// useMember records a read of the property accessed by a member expression, which plays the PropertyRole.
+// e is the member or private member expression, whose object and computed key should already be visited.
+func (lv *DfaVisitor) useMember(e ast.Expr) {
+	base, path, ok := accessPath(e, lv.Ctx.thisName)
+	if !ok || lv.Ctx.Ignored[base.Name] {
+		return
+	}
+
+	lv.Ctx.UseDefs = append(lv.Ctx.UseDefs, &UseDef{
+		Usage:       base,
+		Path:        path,
+		Definitions: pathDefs(lv.Ctx.scopeStack[lv.Ctx.scopeDepth], base.Name, path),
+		Role:        PropertyRole,
+	})
+}
+
+// assignMember records an assignment to a property as a definition of its access path.
+// The object and any computed keys are evaluated as uses before the property is written.
+// e is the member or private member expression being assigned to.
+// v is the expression assigned to it, or nil if it's unknown.
+// overwrite denotes if the definition replaces the previous definitions of the property.
+func (lv *DfaVisitor) assignMember(e ast.Expr, v *ast.Expression, overwrite bool) {
+	lv.visitObject(e)
+
+	base, path, ok := accessPath(e, lv.Ctx.thisName)
+	if !ok {
+		return
+	}
+
+	lv.defineProperty(base.Name, path, v, overwrite)
+}
+
+// defineProperty defines a property of a binding in the current scope.
+// base is the name of the binding, and path is the chain of property names.
+// v is the expression assigned to the property, or nil if it's unknown.
+// overwrite denotes if the definition replaces the previous definitions of the property.
+func (lv *DfaVisitor) defineProperty(base string, path []string, v *ast.Expression, overwrite bool) {
+	key := pathKey(base, path)
+	typ, depth := lv.Ctx.lookupDef(base)
+	lv.Ctx.assignProperty(&lv.Ctx.defCount, lv.Ctx.scopeStack[lv.Ctx.scopeDepth], key, v, overwrite, lv.Ctx.conditionalAssignment(key), typ, depth)
+}
+
+// assignProperty defines a property in a scope, which both engines assign properties with.
+// count is the count of the definition, which is advanced past it.
+// key is the access path of the property, and v is the expression assigned to it, or nil if it's unknown.
+// overwrite denotes if the definition replaces the previous definitions of the property,
+// and conditional depicts if the assignment only runs conditionally relative to the definitions it would replace.
+// typ and depth are the type and depth of the binding the property belongs to.
+func (r *rdaContext) assignProperty(count *int64, s *Scope, key string, v *ast.Expression, overwrite bool, conditional bool, typ ScopeDefType, depth int) {
+	// A property that can't be resolved statically may not be the one a later read refers to.
+	overwrite = overwrite && !strings.Contains(key, anyProperty) && !conditional
+
+	// A property that's never been assigned holds whatever the object it belongs to was defined with.
+	if _, ok := s.Definitions[key]; !ok {
+		s.Definitions[key] = []*ScopeDef{Undefined}
+	}
+
+	r.addValueAt(count, s, key, v, overwrite, typ, depth)
+}
+
+// copyProperties replaces the definitions of every property of a binding in dst with the ones in src.
+// base is the name of the binding.
+func copyProperties(dst *Scope, src *Scope, base string) {
+	dst.killProperties(base, true)
+
+	for id, defs := range src.Definitions {
+		if strings.HasPrefix(id, base+".") || strings.HasPrefix(id, base+anyProperty) {
+			dst.Definitions[id] = append([]*ScopeDef{}, defs...)
+		}
+	}
+}
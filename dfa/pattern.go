@@ -0,0 +1,174 @@
+package dfa
+
+import "github.com/t14raptor/go-fast/ast"
+
+// defineFunc defines a single identifier bound by a binding target.
+// id is the identifier, and v is the expression it takes its value from, or nil if it's unknown.
+// overwrite denotes if the definition replaces the previous definitions of the identifier.
+type defineFunc func(id *ast.Identifier, v *ast.Expression, overwrite bool)
+
+// bindPattern defines every identifier bound by a binding target, each linked to the part of the source it's taken from.
+// Default values are visited as uses, and define their identifier conditionally on top of the value from the source.
+// target is the identifier or destructuring pattern being bound.
+// source is the expression the target takes its value from, or nil if it's unknown.
+// overwrite denotes if the definitions replace the previous definitions of the identifiers.
+// define is called for every identifier bound by the target.
+func (lv *DfaVisitor) bindPattern(target ast.Expr, source *ast.Expression, overwrite bool, define defineFunc) {
+	switch t := target.(type) {
+	case *ast.Identifier:
+		define(t, source, overwrite)
+	case *ast.AssignExpression:
+		// The default value is only used when the source is undefined.
+		lv.VisitExpression(t.Right)
+		lv.bindPattern(t.Left.Expr, source, overwrite, define)
+		lv.bindPattern(t.Left.Expr, t.Right, false, define)
+	case *ast.ArrayPattern:
+		for i := range t.Elements {
+			// Elisions don't bind anything.
+			if t.Elements[i].Expr == nil {
+				continue
+			}
+
+			lv.bindPattern(t.Elements[i].Expr, elementSource(source, i), overwrite, define)
+		}
+
+		if t.Rest != nil && t.Rest.Expr != nil {
+			lv.bindPattern(t.Rest.Expr, source, overwrite, define)
+		}
+	case *ast.ObjectPattern:
+		for i := range t.Properties {
+			switch p := t.Properties[i].Prop.(type) {
+			case *ast.PropertyShort:
+				v := propertySource(source, p.Name.Name)
+
+				if p.Initializer != nil && p.Initializer.Expr != nil {
+					lv.VisitExpression(p.Initializer)
+					define(p.Name, v, overwrite)
+					define(p.Name, p.Initializer, false)
+					continue
+				}
+
+				define(p.Name, v, overwrite)
+			case *ast.PropertyKeyed:
+				var v *ast.Expression
+				if key, ok := p.Key.Expr.(*ast.StringLiteral); ok && !p.Computed {
+					v = propertySource(source, key.Value)
+				} else {
+					// Computed keys are evaluated before the property is bound.
+					lv.VisitExpression(p.Key)
+					v = computedSource(source, p.Key)
+				}
+
+				lv.bindPattern(p.Value.Expr, v, overwrite, define)
+			}
+		}
+
+		if t.Rest != nil {
+			lv.bindPattern(t.Rest, source, overwrite, define)
+		}
+	case *ast.MemberExpression, *ast.PrivateDotExpression:
+		lv.assignMember(t, source, overwrite)
+	}
+}
+
+// elementSource returns the part of a source expression an array pattern's element takes its value from.
+// Array literals resolve to the element itself, and any other source to an index into it.
+// source is the expression being destructured, and idx is the index of the element.
+func elementSource(source *ast.Expression, idx int) *ast.Expression {
+	if source == nil {
+		return nil
+	}
+
+	if lit, ok := source.Expr.(*ast.ArrayLiteral); ok {
+		for i := 0; i < len(lit.Value) && i <= idx; i++ {
+			// Elements after a spread element can't be matched up with their index.
+			if _, ok := lit.Value[i].Expr.(*ast.SpreadElement); ok {
+				return computedSource(source, &ast.Expression{Expr: &ast.NumberLiteral{Value: float64(idx)}})
+			}
+		}
+
+		if idx < len(lit.Value) {
+			return &lit.Value[idx]
+		}
+	}
+
+	return computedSource(source, &ast.Expression{Expr: &ast.NumberLiteral{Value: float64(idx)}})
+}
+
+// propertySource returns the part of a source expression an object pattern's property takes its value from.
+// Object literals resolve to the value of the property, and any other source to a member expression on it.
+// source is the expression being destructured, and key is the name of the property.
+func propertySource(source *ast.Expression, key string) *ast.Expression {
+	if source == nil {
+		return nil
+	}
+
+	if lit, ok := source.Expr.(*ast.ObjectLiteral); ok {
+		for i := range lit.Value {
+			switch p := lit.Value[i].Prop.(type) {
+			case *ast.PropertyShort:
+				if p.Name.Name == key {
+					return &ast.Expression{Expr: p.Name}
+				}
+			case *ast.PropertyKeyed:
+				if k, ok := p.Key.Expr.(*ast.StringLiteral); ok && !p.Computed && k.Value == key {
+					return p.Value
+				}
+			}
+		}
+	}
+
+	return &ast.Expression{Expr: &ast.MemberExpression{
+		Object:   source,
+		Property: &ast.MemberProperty{Prop: &ast.Identifier{Name: key}},
+	}}
+}
+
+// computedSource returns a computed member expression on a source expression.
+// source is the expression being destructured, and key is the expression of the property.
+func computedSource(source *ast.Expression, key *ast.Expression) *ast.Expression {
+	if source == nil {
+		return nil
+	}
+
+	return &ast.Expression{Expr: &ast.MemberExpression{
+		Object:   source,
+		Property: &ast.MemberProperty{Prop: &ast.ComputedProperty{Expr: key}},
+	}}
+}
+
+// boundIdentifiers returns every identifier bound by a binding target, in the order they're bound.
+// target is the identifier or destructuring pattern.
+func boundIdentifiers(target ast.Expr) []*ast.Identifier {
+	switch t := target.(type) {
+	case *ast.Identifier:
+		return []*ast.Identifier{t}
+	case *ast.AssignExpression:
+		return boundIdentifiers(t.Left.Expr)
+	case *ast.ArrayPattern:
+		ids := []*ast.Identifier{}
+		for i := range t.Elements {
+			ids = append(ids, boundIdentifiers(t.Elements[i].Expr)...)
+		}
+
+		if t.Rest != nil {
+			ids = append(ids, boundIdentifiers(t.Rest.Expr)...)
+		}
+
+		return ids
+	case *ast.ObjectPattern:
+		ids := []*ast.Identifier{}
+		for i := range t.Properties {
+			switch p := t.Properties[i].Prop.(type) {
+			case *ast.PropertyShort:
+				ids = append(ids, p.Name)
+			case *ast.PropertyKeyed:
+				ids = append(ids, boundIdentifiers(p.Value.Expr)...)
+			}
+		}
+
+		return append(ids, boundIdentifiers(t.Rest)...)
+	}
+
+	return nil
+}
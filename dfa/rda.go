@@ -15,8 +15,145 @@ type rdaContext struct {
 	scopeStack         []*Scope
 	Debug              bool
 	UseDefs            []*UseDef
+	// Globals holds the names of the globals provided by the environment the analyzed code runs in.
+	Globals map[string]bool
+	// Ignored holds the names that never produce a UseDef.
+	Ignored map[string]bool
+
+	// handlers holds a scope per enclosing try statement, collecting the definitions that may be live when an exception is thrown.
+	handlers []*Scope
+	// targets holds the enclosing statements that break and continue statements can jump to.
+	targets []*jumpTarget
+	// finallies holds the enclosing try statements with a finally block, which break and continue statements leaving them run first.
+	finallies []*finallyEntry
+	// labels holds the labels waiting to be attached to the next loop or switch statement.
+	labels []string
+	// loops holds the loops enclosing the code being visited, outermost first.
+	loops []loopEntry
+	// backEdges holds the back edge of every loop visited inside of the outermost loop, which is carried over between its passes.
+	backEdges map[ast.VisitableNode]*Scope
+	// loopChanged depicts if any back edge gained definitions during the current pass of the outermost loop.
+	loopChanged bool
+	// captures holds the uses of variables inside of closures.
+	captures []*capture
+	// closures holds the range of definitions made inside of every function.
+	closures []closureRange
+	// functions holds the functions enclosing the code being visited, outermost first.
+	functions []functionEntry
+	// hoisted holds every function declaration hoisted to the top of its function scope.
+	hoisted map[*ast.FunctionDeclaration]functionEntry
+	// thisName is the name of the binding this refers to, or an empty string if it can't be resolved.
+	// Properties of this are defined as properties of that binding.
+	thisName string
+	// instance holds the properties of this once the constructor of the class being visited returns.
+	instance *Scope
+	// tdzDefs holds the temporal dead zone definition of every lexical binding, keyed by its declaration.
+	tdzDefs map[*ast.Identifier]*ScopeDef
+	// envDefs holds the definition of every global provided by the environment, keyed by its name.
+	envDefs map[string]*ScopeDef
+	// argumentsDefs holds the implicit definition of arguments in every function that isn't an arrow function, keyed by the function.
+	argumentsDefs map[ast.VisitableNode]*ScopeDef
+
+	// defCount is the count of the next definition, which numbers definitions in the order they're made.
+	defCount int64
+	// defRegistry holds every definition created during the analysis, keyed by its count.
+	// Loop bodies are visited repeatedly until their definitions reach a fixpoint, so revisiting
+	// a definition must resolve to the same *ScopeDef for pointer comparisons to hold.
+	defRegistry map[int64]*ScopeDef
+
+	// defUses holds the usages every definition reaches, which is the inverse of UseDefs.
+	defUses map[*ScopeDef][]*UseDef
+	// defs holds every definition made by the analyzed code, in the order they're made.
+	defs []*ScopeDef
+
+	// Engine is the engine computing the reaching definitions, which is the WalkEngine by default.
+	Engine Engine
 }
 
+// functionEntry is a function enclosing the code being visited.
+type functionEntry struct {
+	// depth is the scope depth of the function's scope.
+	depth int
+	// created is the definition count the function was created at.
+	created int64
+	// entry holds the definitions when the function was created, if it was created before the code around it ran.
+	entry *Scope
+}
+
+// loopEntry is a loop enclosing the code being visited.
+type loopEntry struct {
+	// depth is the scope depth the loop is in.
+	depth int
+	// count is the first definition count inside of the loop.
+	count int64
+}
+
+// visitMark is the point a visit reached, which it can be rewound to in order to visit the same code again.
+type visitMark struct {
+	count                    int64
+	uses, captures, closures int
+}
+
+// capture is a use of a variable inside of a closure, which may observe definitions made after the closure was created.
+type capture struct {
+	useDef *UseDef
+	// owner is the scope the captured variable belongs to, and depth is the depth of that scope.
+	owner *Scope
+	depth int
+	// after is the first definition count that may run after the closure was created.
+	after int64
+}
+
+// closureRange is the range of definition counts made inside of a function.
+type closureRange struct {
+	// depth is the scope depth of the function's scope.
+	depth      int
+	start, end int64
+}
+
+// jumpTarget is a statement that break and continue statements can jump to.
+type jumpTarget struct {
+	labels []string
+	// loop depicts if the target is a loop, which continue statements can jump to.
+	loop bool
+	// breakable depicts if unlabelled break statements can jump to the target.
+	breakable bool
+	// breaks holds the definitions at every break statement jumping to the target.
+	breaks []*Scope
+	// continues holds the definitions at every continue statement jumping to the target.
+	continues []*Scope
+}
+
+// finallyEntry is a try statement with a finally block enclosing the code being visited.
+type finallyEntry struct {
+	// targets is the number of jump targets enclosing the try statement.
+	targets int
+	// jumps holds the break and continue statements leaving the try statement, which reach their target once the finally block ran.
+	jumps []pendingJump
+}
+
+// pendingJump is a break or continue statement waiting for a finally block to run before reaching its target.
+type pendingJump struct {
+	label string
+	cont  bool
+	// state holds the definitions at the statement.
+	state *Scope
+}
+
+// hasLabel determines if the target is labelled with the given label.
+func (t *jumpTarget) hasLabel(label string) bool {
+	for _, l := range t.labels {
+		if l == label {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ScopeDefs holds the definitions of every binding, and of every property of a binding keyed by its access path.
+// Access paths are keyed by the name of the binding followed by its chain of property names, such as "obj.a.b" or "obj[].b",
+// where "[]" is a computed property that may be any property.
 type ScopeDefs map[string][]*ScopeDef
 
 func (s ScopeDefs) AppendScopeDefs(src ScopeDefs) {
@@ -25,8 +162,6 @@ func (s ScopeDefs) AppendScopeDefs(src ScopeDefs) {
 	}
 }
 
-var DefCount int64
-
 var Undefined = &ScopeDef{
 	Val:   nil,
 	Typ:   FunctionScope,
@@ -42,16 +177,34 @@ const (
 )
 
 type ScopeDef struct {
+	// Id is the name of the binding, or the key of the access path for definitions of a property.
+	Id    string
 	Val   *ast.Expression
 	Depth int
 	Typ   ScopeDefType
 	Count int64
+	// TDZ depicts if the definition is a lexical binding that hasn't been initialized yet.
+	TDZ bool
+	// Environment depicts if the definition is a global provided by the environment rather than the analyzed code.
+	Environment bool
 }
 
 type Scope struct {
 	Conditional   bool
 	FunctionScope bool
-	Definitions   ScopeDefs
+	// CaseScope depicts if the scope is a switch case, which shares the lexical scope of its switch statement.
+	CaseScope bool
+	// Unreachable depicts if the end of the scope can't be reached, as every path through it jumped elsewhere.
+	// The definitions of an unreachable scope never fall through into the code following it.
+	Unreachable bool
+	Definitions ScopeDefs
+
+	// start is the definition count when the scope was pushed.
+	start int64
+	// vars holds the names declared with var in a function scope.
+	vars map[string]bool
+	// lexicals holds the names declared with let, const and class in the scope, which shadow the bindings outside of it.
+	lexicals map[string]bool
 }
 
 // NewScope creates a new scope.
@@ -65,37 +218,72 @@ func NewScope(cond bool, funcscope bool) *Scope {
 	}
 }
 
-// AddValue adds a definition to the scope.
+// addValue adds a definition to a scope, numbered with the definition count of the walk.
+// s denotes the scope.
 // id denotes the identifier.
 // v denotes the expression.
 // overwrite denotes if the value overwrites previous declarations in this scope.
 // typ denotes the type of definition (block, function, global)
 // depth is the depth that the declaration expires at.
-func (s *Scope) AddValue(id string, v *ast.Expression, overwrite bool, typ ScopeDefType, depth int) {
-	val := &ScopeDef{
-		Val:   v,
-		Typ:   typ,
-		Depth: depth,
-		Count: DefCount,
-	}
+func (r *rdaContext) addValue(s *Scope, id string, v *ast.Expression, overwrite bool, typ ScopeDefType, depth int) {
+	r.addValueAt(&r.defCount, s, id, v, overwrite, typ, depth)
+}
 
-	if v == nil {
-		val = Undefined
+// addValueAt adds a definition to a scope, like addValue.
+// count is the count of the definition, which is advanced past it and the definitions of the properties it initializes.
+func (r *rdaContext) addValueAt(count *int64, s *Scope, id string, v *ast.Expression, overwrite bool, typ ScopeDefType, depth int) {
+	val := Undefined
+	if v != nil {
+		val = r.newScopeDef(*count, id, v, typ, depth)
 	}
 
-	DefCount++
+	*count++
 
 	if overwrite {
 		s.Definitions[id] = []*ScopeDef{val}
-		return
+	} else if defs, ok := s.Definitions[id]; ok {
+		// The definition may already be present if it was carried along a loop's back edge.
+		if !s.HasDef(id, val) {
+			s.Definitions[id] = append(defs, val)
+		}
+	} else {
+		s.Definitions[id] = []*ScopeDef{val}
 	}
 
-	if v, ok := s.Definitions[id]; ok {
-		s.Definitions[id] = append(v, val)
-		return
+	// The properties of the previous value don't belong to the new one.
+	s.killProperties(id, overwrite)
+
+	if v != nil {
+		if lit, ok := v.Expr.(*ast.ObjectLiteral); ok {
+			r.addProperties(count, s, id, lit, overwrite, typ, depth)
+		}
+	}
+}
+
+// newScopeDef creates the definition with the given count, or returns it if it was already
+// created by a previous pass over the same code.
+func (r *rdaContext) newScopeDef(count int64, id string, v *ast.Expression, typ ScopeDefType, depth int) *ScopeDef {
+	if def, ok := r.defRegistry[count]; ok {
+		return def
+	}
+
+	def := &ScopeDef{
+		Id:    id,
+		Val:   v,
+		Typ:   typ,
+		Depth: depth,
+		Count: count,
 	}
 
-	s.Definitions[id] = []*ScopeDef{val}
+	r.defRegistry[count] = def
+	return def
+}
+
+// Copy creates a snapshot of the definitions in the scope.
+func (s *Scope) Copy() *Scope {
+	c := NewScope(s.Conditional, s.FunctionScope)
+	c.Definitions.AppendScopeDefs(s.Definitions)
+	return c
 }
 
 // Get retrieves a list of definitions for an identifier in that scope.
@@ -115,16 +303,26 @@ func (s *Scope) HasDef(id string, def *ScopeDef) bool {
 	return false
 }
 
-// RemoveParentDefs removes all ScopeDefs that exist in the parent scope.
-// This prevents propogating definitions that already exist into the parent scope.
-func (s *Scope) RemoveParentDefs(parentScope *Scope) {
-	for id, defs := range s.Definitions {
-		for idx, def := range defs {
-			if parentScope.HasDef(id, def) {
-				s.Definitions[id][idx] = nil
-			}
+// sameDefs determines if two lists hold the same set of definitions.
+func sameDefs(a []*ScopeDef, b []*ScopeDef) bool {
+	for _, def := range a {
+		if def != nil && !containsDef(b, def) {
+			return false
+		}
+	}
+
+	for _, def := range b {
+		if def != nil && !containsDef(a, def) {
+			return false
 		}
 	}
+
+	return true
+}
+
+// binds determines if the scope holds a binding for an identifier, even if it's undefined or in its temporal dead zone.
+func (s *Scope) binds(id string) bool {
+	return len(s.Definitions[id]) > 0 || s.vars[id]
 }
 
 // MergeSameDepth merges defintions from scope A and scope B, storing the definitions in scope A.
@@ -153,18 +351,8 @@ func (s *Scope) MergeDefs(defs []*ScopeDef, id string) {
 
 		s.Definitions[id] = append(orig, original...)
 	} else {
-		s.Definitions[id] = defs
-	}
-}
-
-// AddUndefined adds Undefined objects to all declarations within this scope.
-// parentScope is used to determine which values were propogated from the parent scope.
-func (s *Scope) AddUndefined(parentScope *Scope) {
-	for id, x := range s.Definitions {
-		// If no value exists in the parent scope, then add undefined.
-		if _, found := parentScope.Get(id); !found && !s.HasDef(id, Undefined) {
-			s.Definitions[id] = append(x, Undefined)
-		}
+		// Copy the definitions so both scopes don't share a backing array.
+		s.Definitions[id] = append([]*ScopeDef{}, defs...)
 	}
 }
 
@@ -182,8 +370,26 @@ func (r *rdaContext) Start(a *ast.Program) {
 		Ctx: r,
 	}
 
-	DefCount = 0
-	a.VisitWith(&dfaVisitor)
+	r.defCount = 0
+	r.defRegistry = make(map[int64]*ScopeDef)
+	r.hoisted = make(map[*ast.FunctionDeclaration]functionEntry)
+	r.tdzDefs = make(map[*ast.Identifier]*ScopeDef)
+	r.thisName = "this"
+	r.envDefs = make(map[string]*ScopeDef)
+	r.argumentsDefs = make(map[ast.VisitableNode]*ScopeDef)
+	r.defineGlobals(a)
+
+	switch r.Engine {
+	case WorklistEngine:
+		r.runWorklist(a)
+	default:
+		a.VisitWith(&dfaVisitor)
+	}
+
+	// Global variables live until the end of the program.
+	r.resolveCaptures(r.scopeStack[0])
+	r.markUnresolved()
+	r.linkUses()
 	if r.Debug {
 		fmt.Println("Definitions:", r.scopeStack[0].Definitions)
 	}
@@ -197,6 +403,7 @@ func (r *rdaContext) pushScope(scope *Scope) {
 		panic("exceeded max scope depth")
 	}
 	r.scopeDepth++
+	scope.start = r.defCount
 
 	if scope.FunctionScope {
 		r.functionScopeDepth = r.scopeDepth
@@ -204,6 +411,11 @@ func (r *rdaContext) pushScope(scope *Scope) {
 
 	scope.Definitions.AppendScopeDefs(r.scopeStack[r.scopeDepth-1].Definitions)
 
+	// Code nested inside unreachable code is also unreachable.
+	if r.scopeStack[r.scopeDepth-1].Unreachable {
+		scope.Unreachable = true
+	}
+
 	r.scopeStack[r.scopeDepth] = scope
 }
 
@@ -224,6 +436,7 @@ func (r *rdaContext) popScope() *Scope {
 		for i := r.scopeDepth; i >= 0; i-- {
 			if r.scopeStack[i].FunctionScope {
 				r.functionScopeDepth = i
+				break
 			}
 		}
 	}
@@ -231,14 +444,29 @@ func (r *rdaContext) popScope() *Scope {
 	if r.Debug {
 		fmt.Println("Scope Defs:", x.Definitions)
 	}
+
+	// Every definition of the variables in the scope is known once it's popped.
+	r.resolveCaptures(x)
 	return x
 }
 
 // mergeDown will merge defintions from the scope "a" into the current scope.
 func (r *rdaContext) mergeDown(scopeDepth int, a *Scope, conditional bool) {
 	parentScope := r.scopeStack[r.scopeDepth]
+
+	// Nothing falls through out of an unreachable scope.
+	if a.Unreachable {
+		if !conditional {
+			parentScope.Unreachable = true
+		}
+		return
+	}
 outer:
 	for id, vals := range a.Definitions {
+		// Bindings declared in the scope, and their properties, disappear along with it.
+		if base, _ := splitPath(id); a.lexicals[base] {
+			continue
+		}
 
 		currentVals := parentScope.Definitions[id]
 		carryVals := []*ScopeDef{}
@@ -299,24 +527,459 @@ outer:
 
 }
 
-func (r *rdaContext) findNotExpiring(s *Scope, id string, blockParents bool) []*ScopeDef {
-	result := []*ScopeDef{}
+// mark returns the point the visit has reached.
+func (r *rdaContext) mark() visitMark {
+	return visitMark{
+		count:    r.defCount,
+		uses:     len(r.UseDefs),
+		captures: len(r.captures),
+		closures: len(r.closures),
+	}
+}
+
+// rewind discards the definition counts and usages recorded since the mark.
+func (r *rdaContext) rewind(m visitMark) {
+	r.defCount = m.count
+	r.UseDefs = r.UseDefs[:m.uses]
+	r.captures = r.captures[:m.captures]
+	r.closures = r.closures[:m.closures]
+}
+
+// collectThrowState merges the current definitions into the innermost exception handler.
+// It should be called at every point an exception may be thrown from.
+func (r *rdaContext) collectThrowState() {
+	if len(r.handlers) == 0 || r.scopeStack[r.scopeDepth].Unreachable {
+		return
+	}
+
+	r.mergeLive(r.handlers[len(r.handlers)-1], r.scopeStack[r.scopeDepth])
+}
+
+// lexicalDepth returns the depth of the innermost scope that block scoped declarations belong to.
+func (r *rdaContext) lexicalDepth() int {
+	depth := r.scopeDepth
+	for depth > 0 && r.scopeStack[depth].CaseScope {
+		depth--
+	}
+
+	return depth
+}
+
+// joinPaths replaces the definitions in the current scope with the join of every path leading out of a statement.
+// Unreachable paths are ignored, and the current scope is only reachable if one of the paths is.
+func (r *rdaContext) joinPaths(paths []*Scope) {
+	live := []*Scope{}
+	for _, p := range paths {
+		if !p.Unreachable {
+			live = append(live, p)
+		}
+	}
+
+	currentScope := r.scopeStack[r.scopeDepth]
+	currentScope.Unreachable = len(live) == 0
+	if currentScope.Unreachable {
+		return
+	}
+
+	currentScope.Definitions = r.mergePaths(live).Definitions
+}
+
+// overlay replaces the definitions of every identifier a finally block redefined on a path leaving it.
+// finallyScope holds only the identifiers the finally block redefined.
+func overlay(path *Scope, finallyScope *Scope) {
+	for id, defs := range finallyScope.Definitions {
+		if base, _ := splitPath(id); !finallyScope.lexicals[base] {
+			path.Definitions[id] = append([]*ScopeDef{}, defs...)
+		}
+	}
+}
+
+// mergePaths joins the definitions of every path leading out of a branching statement into a single scope.
+// Definitions that already reached the statement keep their original order, followed by new definitions in path order.
+// Identifiers that don't exist in the current scope are undefined on any path that doesn't define them.
+func (r *rdaContext) mergePaths(paths []*Scope) *Scope {
+	union := NewScope(false, false)
+	for _, p := range paths {
+		r.mergeLive(union, p)
+	}
+
+	currentScope := r.scopeStack[r.scopeDepth]
+	joined := NewScope(false, false)
+	for id, defs := range union.Definitions {
+		joined.Definitions[id] = []*ScopeDef{}
+		for _, def := range currentScope.Definitions[id] {
+			if def != nil && union.HasDef(id, def) {
+				joined.Definitions[id] = append(joined.Definitions[id], def)
+			}
+		}
+
+		joined.MergeDefs(defs, id)
+
+		if _, found := currentScope.Get(id); found {
+			continue
+		}
+
+		for _, p := range paths {
+			if !r.isLive(p, id) {
+				joined.MergeDefs([]*ScopeDef{Undefined}, id)
+				break
+			}
+		}
+	}
+
+	return joined
+}
+
+// isLive determines if the scope holds any definitions for an identifier that haven't expired at the current depth.
+func (r *rdaContext) isLive(s *Scope, id string) bool {
 	for _, val := range s.Definitions[id] {
-		if val.Typ == GlobalScope {
-			result = append(result, val)
+		if val == nil {
 			continue
 		}
 
-		if blockParents && r.scopeStack[r.scopeDepth].HasDef(id, val) {
+		if val.Typ == BlockScope && r.scopeDepth < val.Depth {
 			continue
 		}
 
-		if r.scopeDepth < val.Depth {
+		return true
+	}
+
+	return false
+}
+
+// takeLabels returns the labels waiting to be attached to the next jump target, and clears them.
+func (r *rdaContext) takeLabels() []string {
+	labels := r.labels
+	r.labels = nil
+	return labels
+}
+
+// pushTarget pushes a new jump target.
+// labels are the labels of the target statement.
+// loop depicts if the target is a loop.
+// breakable depicts if unlabelled break statements can jump to the target.
+func (r *rdaContext) pushTarget(labels []string, loop bool, breakable bool) *jumpTarget {
+	t := &jumpTarget{
+		labels:    labels,
+		loop:      loop,
+		breakable: breakable,
+	}
+
+	r.targets = append(r.targets, t)
+	return t
+}
+
+func (r *rdaContext) popTarget() {
+	r.targets = r.targets[:len(r.targets)-1]
+}
+
+// jump carries the current definitions to the target of a break or continue statement,
+// and makes the rest of the current scope unreachable.
+// label is the label of the statement, or an empty string if it has none.
+// cont depicts if the statement is a continue statement.
+func (r *rdaContext) jump(label string, cont bool) {
+	currentScope := r.scopeStack[r.scopeDepth]
+	if currentScope.Unreachable {
+		return
+	}
+
+	r.deliver(label, cont, currentScope.Copy())
+	currentScope.Unreachable = true
+}
+
+// deliver carries the definitions of a break or continue statement to its target.
+// Jumps leaving a try statement with a finally block are held back until the finally block ran.
+func (r *rdaContext) deliver(label string, cont bool, state *Scope) {
+	for i := len(r.targets) - 1; i >= 0; i-- {
+		t := r.targets[i]
+
+		if label != "" && !t.hasLabel(label) {
 			continue
 		}
 
-		result = append(result, val)
+		if cont && !t.loop || !cont && label == "" && !t.breakable {
+			continue
+		}
+
+		if n := len(r.finallies); n > 0 && r.finallies[n-1].targets > i {
+			f := r.finallies[n-1]
+			f.jumps = append(f.jumps, pendingJump{label: label, cont: cont, state: state})
+			return
+		}
+
+		if cont {
+			t.continues = append(t.continues, state)
+		} else {
+			t.breaks = append(t.breaks, state)
+		}
+		return
+	}
+}
+
+// captureUse marks a use as a closure capture if the variable it uses belongs to a scope outside of the current function.
+// ud is the use, and id is the identifier it uses.
+func (r *rdaContext) captureUse(ud *UseDef, id string) {
+	_, depth := r.lookupDef(id)
+	if depth >= r.functionScopeDepth {
+		return
+	}
+
+	// The closure may run as soon as the outermost function inside of the variable's scope is created.
+	f := functionEntry{created: r.defCount}
+	for _, e := range r.functions {
+		if e.depth > depth {
+			f = e
+			break
+		}
+	}
+
+	loop := int64(-1)
+	for _, l := range r.loops {
+		if l.depth >= depth {
+			loop = l.count
+			break
+		}
+	}
+
+	var entry ScopeDefs
+	if f.entry != nil {
+		entry = f.entry.Definitions
+	}
+
+	after, ok := markCaptured(ud, r.scopeStack[depth].binds(id), f.created, entry, id, loop)
+	if !ok {
+		return
+	}
+
+	r.captures = append(r.captures, &capture{
+		useDef: ud,
+		owner:  r.scopeStack[depth],
+		depth:  depth,
+		after:  after,
+	})
+}
+
+// markCaptured marks a use as a closure capture, which both engines find captures with.
+// Returns the first definition count of the variable that may run after the closure was created, or false if the use isn't captured.
+// bound depicts if an enclosing scope binds the variable.
+// created is the definition count the outermost function inside of the variable's scope was created at.
+// hoisted holds the definitions when that function was hoisted, or nil if it isn't hoisted, where key is the key of the variable.
+// loop is the definition count the outermost loop around that function inside of the variable's scope started at, or -1 if there's none.
+func markCaptured(ud *UseDef, bound bool, created int64, hoisted ScopeDefs, key string, loop int64) (int64, bool) {
+	// Names that no enclosing scope binds, such as undeclared globals, aren't captured.
+	if !bound {
+		return 0, false
+	}
+
+	ud.Captured = true
+
+	// Hoisted functions see the definitions from when they were hoisted, rather than where they're written.
+	if hoisted != nil {
+		ud.Definitions = append([]*ScopeDef{}, hoisted[key]...)
+	}
+
+	// Definitions made earlier in a loop around the closure also run after it was created, on the next iteration.
+	if loop >= 0 && loop < created {
+		return loop, true
+	}
+
+	return created, true
+}
+
+// reachesCapture determines if a definition of a captured variable may run after the closure was created.
+// count is the count of the definition, and after is the first count that may run after the closure was created.
+// closure depicts if the definition is made inside of another closure.
+func reachesCapture(count int64, after int64, closure bool) bool {
+	// Definitions inside of other closures may run whenever those closures are called.
+	return count >= after || closure
+}
+
+// resolveCaptures adds every definition of a captured variable that may run after its closure was created to the capture.
+// owner is the scope being popped, whose variables can no longer be defined.
+func (r *rdaContext) resolveCaptures(owner *Scope) {
+	for _, c := range r.captures {
+		if c.owner != owner {
+			continue
+		}
+
+		defs := append([]*ScopeDef{}, c.useDef.Definitions...)
+		for count := owner.start; count < r.defCount; count++ {
+			def, ok := r.defRegistry[count]
+			if !ok || def.Id != c.useDef.Usage.Name || def.Depth != c.depth {
+				continue
+			}
+
+			if reachesCapture(count, c.after, r.inClosure(count, c.depth)) && !containsDef(defs, def) {
+				defs = append(defs, def)
+			}
+		}
+
+		c.useDef.Definitions = defs
+	}
+}
+
+// inClosure determines if a definition was made inside of a function nested deeper than the given depth.
+func (r *rdaContext) inClosure(count int64, depth int) bool {
+	for _, c := range r.closures {
+		if c.depth > depth && c.start <= count && count < c.end {
+			return true
+		}
+	}
+
+	return false
+}
+
+// definedInScope determines if an identifier was defined in a scope itself, rather than inherited from its parents.
+func (r *rdaContext) definedInScope(s *Scope, id string) bool {
+	for _, def := range s.Definitions[id] {
+		if def != nil && def != Undefined && def.Count >= s.start {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsDef determines if a list of definitions contains the definition.
+func containsDef(list []*ScopeDef, def *ScopeDef) bool {
+	for _, d := range list {
+		if d == def {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tdzDef returns the temporal dead zone definition of a lexical binding.
+// id is the identifier the binding is declared with, and depth is the depth that the binding expires at.
+func (r *rdaContext) tdzDef(id *ast.Identifier, depth int) *ScopeDef {
+	if def, ok := r.tdzDefs[id]; ok {
+		return def
+	}
+
+	def := &ScopeDef{
+		Id:    id.Name,
+		Typ:   BlockScope,
+		Depth: depth,
+		Count: -1,
+		TDZ:   true,
+	}
+
+	r.tdzDefs[id] = def
+	return def
+}
+
+// argumentsDef returns the implicit definition of arguments in a function.
+// fn is the node the function is created by, and depth is the depth of its function scope.
+func (r *rdaContext) argumentsDef(fn ast.VisitableNode, depth int) *ScopeDef {
+	if def, ok := r.argumentsDefs[fn]; ok {
+		return def
+	}
+
+	def := &ScopeDef{
+		Id:    "arguments",
+		Typ:   FunctionScope,
+		Depth: depth,
+		Count: -1,
+	}
+
+	r.argumentsDefs[fn] = def
+	return def
+}
+
+// checkTDZ marks a use that may happen before its binding is initialized, removing the binding's temporal dead zone from its definitions.
+// Closures usually run after the bindings they capture are initialized, so captured uses are never marked.
+func (r *rdaContext) checkTDZ(ud *UseDef) {
+	defs := []*ScopeDef{}
+	for _, def := range ud.Definitions {
+		if def != nil && def.TDZ {
+			ud.TDZ = !ud.Captured
+			continue
+		}
+
+		defs = append(defs, def)
+	}
+
+	ud.Definitions = defs
+}
+
+// Diagnostics returns the problems found in the analyzed code.
+func (r *rdaContext) Diagnostics() []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, ud := range r.UseDefs {
+		if ud.TDZ {
+			diagnostics = append(diagnostics, Diagnostic{
+				Usage:   ud.Usage,
+				Message: fmt.Sprintf("%s is used before it's initialized", ud.Usage.Name),
+			})
+		}
+	}
+
+	return diagnostics
+}
+
+// exit makes the rest of the current scope unreachable, as control leaves it without reaching a jump target.
+func (r *rdaContext) exit() {
+	r.scopeStack[r.scopeDepth].Unreachable = true
+}
+
+// lookupDef finds the type and expiry depth of the closest definition of an identifier.
+// Identifiers that have never been defined are treated as implicit globals.
+func (r *rdaContext) lookupDef(id string) (ScopeDefType, int) {
+	for i := r.scopeDepth; i >= 0; i-- {
+		for _, x := range r.scopeStack[i].Definitions[id] {
+			if x == nil || x == Undefined {
+				continue
+			}
+
+			return x.Typ, x.Depth
+		}
+
+		// Variables declared with var belong to their function scope, even while they're undefined.
+		if r.scopeStack[i].FunctionScope && r.scopeStack[i].vars[id] {
+			return FunctionScope, i
+		}
+	}
+
+	return GlobalScope, 0
+}
+
+// conditionalAssignment depicts if an assignment to an identifier from the current scope only runs conditionally
+// relative to the scope its definitions live in, so it can't replace them.
+func (r *rdaContext) conditionalAssignment(id string) bool {
+	for i := r.scopeDepth; i >= 0; i-- {
+		if r.scopeStack[i].Conditional && i != r.scopeDepth {
+			return true
+		}
+
+		if f := r.scopeStack[i].Definitions[id]; len(f) > 0 {
+			break
+		}
+	}
+
+	return false
+}
+
+// mergeLive merges the definitions from the scope "a" that haven't expired at the current depth into dst.
+// This is used to carry definitions along edges that skip the regular merge rules, such as a loop's back edge.
+// Returns true if dst gained any new definitions.
+func (r *rdaContext) mergeLive(dst *Scope, a *Scope) bool {
+	changed := false
+	for id, vals := range a.Definitions {
+		for _, val := range vals {
+			if val == nil || dst.HasDef(id, val) {
+				continue
+			}
+
+			if val.Typ == BlockScope && r.scopeDepth < val.Depth {
+				continue
+			}
+
+			dst.Definitions[id] = append(dst.Definitions[id], val)
+			changed = true
+		}
 	}
 
-	return result
+	return changed
 }
@@ -0,0 +1,440 @@
+package dfa
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/t14raptor/go-fast/ast"
+)
+
+// Value is a single definition of a variable in static single assignment form, which every use of the variable refers to exactly one of.
+type Value struct {
+	Var Variable
+	// Version tells the value apart from the other values of the variable in its graph.
+	// Version 0 is the value the variable holds when the function starts, such as an argument or the value of an outer variable.
+	Version int
+	// Item is the item assigning the value, or nil if it's the value the variable starts with or it's joined by a phi node.
+	Item *Item
+	// Id is the identifier the item assigns the value through.
+	Id *ast.Identifier
+	// Phi is the phi node joining the value, or nil if it isn't joined by one.
+	Phi *Phi
+}
+
+func (v *Value) String() string {
+	return v.Var.String() + "#" + strconv.Itoa(v.Version)
+}
+
+// Phi is a node joining the values a variable holds on every edge entering a graph node.
+type Phi struct {
+	Value *Value
+	Node  *GraphNode
+	// Args holds the value flowing along every edge entering the node, in the same order as its Incoming edges.
+	// Edges from nodes that can't be reached hold nil.
+	Args []*Value
+}
+
+// SSA is the static single assignment form of the graphs of a program, in which every variable is assigned exactly once.
+// Phi nodes are only placed where their variable is live, and variables assigned by the functions a graph creates
+// are treated as if they only change where the graph assigns them.
+// Finally blocks are built once for every path leaving them, so the identifiers in them have a value in every copy.
+type SSA struct {
+	CFG *CFG
+	// Phis holds the phi nodes at the start of every node, in the order their variables are first accessed.
+	Phis map[*GraphNode][]*Phi
+
+	idom map[*GraphNode]*GraphNode
+	// uses holds the value every identifier an item reads refers to.
+	uses map[ssaRef]*Value
+	// defs holds the value every identifier an item writes defines.
+	defs map[ssaRef]*Value
+	// values holds the values every item defines, in the order it assigns them.
+	values map[*Item][]*Value
+}
+
+// ssaRef is an access of a variable through an identifier by an item.
+type ssaRef struct {
+	item *Item
+	id   *ast.Identifier
+}
+
+// BuildSSA converts every graph of a control flow graph into static single assignment form.
+func BuildSSA(c *CFG) *SSA {
+	s := &SSA{
+		CFG:    c,
+		Phis:   make(map[*GraphNode][]*Phi),
+		idom:   make(map[*GraphNode]*GraphNode),
+		uses:   make(map[ssaRef]*Value),
+		defs:   make(map[ssaRef]*Value),
+		values: make(map[*Item][]*Value),
+	}
+
+	live := NewLiveness(c)
+	for _, g := range c.Functions {
+		s.build(g, SolveBackward(g, live))
+	}
+
+	return s
+}
+
+// Use returns the value an identifier read by an item refers to, or nil if the item doesn't read it.
+func (s *SSA) Use(item *Item, id *ast.Identifier) *Value {
+	return s.uses[ssaRef{item, id}]
+}
+
+// Def returns the value an identifier written by an item defines, or nil if the item doesn't write it.
+func (s *SSA) Def(item *Item, id *ast.Identifier) *Value {
+	return s.defs[ssaRef{item, id}]
+}
+
+// Dominator returns the immediate dominator of a node, or nil for the entry of a graph and nodes that can't be reached.
+func (s *SSA) Dominator(n *GraphNode) *GraphNode {
+	if d := s.idom[n]; d != n {
+		return d
+	}
+
+	return nil
+}
+
+// build converts a single graph, whose live variables are known.
+func (s *SSA) build(g *FunctionGraph, live *Facts[VarSet]) {
+	order := reversePostorder(g)
+	s.dominators(order)
+	frontiers := s.frontiers(order)
+
+	// Every variable is assigned at the entry of the graph, with the value it starts with.
+	var vars []Variable
+	sites := make(map[Variable][]*GraphNode)
+	for _, n := range order {
+		for _, item := range n.Items {
+			for _, a := range s.CFG.Accesses(item) {
+				if a.Deferred || !a.Direct() {
+					continue
+				}
+
+				if _, ok := sites[a.Var]; !ok {
+					vars = append(vars, a.Var)
+					sites[a.Var] = []*GraphNode{g.Entry}
+				}
+
+				if a.Write {
+					sites[a.Var] = append(sites[a.Var], n)
+				}
+			}
+		}
+	}
+
+	for _, v := range vars {
+		placed := make(map[*GraphNode]bool)
+		work := append([]*GraphNode{}, sites[v]...)
+		assigned := make(map[*GraphNode]bool)
+		for _, n := range work {
+			assigned[n] = true
+		}
+
+		for len(work) > 0 {
+			n := work[len(work)-1]
+			work = work[:len(work)-1]
+
+			for _, y := range frontiers[n] {
+				if placed[y] || !live.In[y][v] {
+					continue
+				}
+
+				placed[y] = true
+				phi := &Phi{Node: y, Args: make([]*Value, len(y.Incoming))}
+				phi.Value = &Value{Var: v, Phi: phi}
+				s.Phis[y] = append(s.Phis[y], phi)
+
+				if !assigned[y] {
+					assigned[y] = true
+					work = append(work, y)
+				}
+			}
+		}
+	}
+
+	r := &ssaRenamer{ssa: s, graph: g, stacks: make(map[Variable][]*Value), versions: make(map[Variable]int), entries: make(map[Variable]*Value)}
+	children := make(map[*GraphNode][]*GraphNode)
+	for _, n := range order[1:] {
+		children[s.idom[n]] = append(children[s.idom[n]], n)
+	}
+
+	r.rename(g.Entry, children, true)
+
+	// Nodes that can't be reached only see the values variables start with.
+	reached := make(map[*GraphNode]bool)
+	for _, n := range order {
+		reached[n] = true
+	}
+
+	for _, n := range g.Nodes {
+		if !reached[n] {
+			r.rename(n, nil, false)
+		}
+	}
+}
+
+// reversePostorder returns the nodes that can be reached from the entry of a graph, in reverse postorder.
+func reversePostorder(g *FunctionGraph) []*GraphNode {
+	visited := make(map[*GraphNode]bool)
+	var post []*GraphNode
+
+	var visit func(n *GraphNode)
+	visit = func(n *GraphNode) {
+		visited[n] = true
+		for _, child := range n.Children {
+			if !visited[child] {
+				visit(child)
+			}
+		}
+
+		post = append(post, n)
+	}
+
+	visit(g.Entry)
+
+	order := make([]*GraphNode, len(post))
+	for i, n := range post {
+		order[len(post)-1-i] = n
+	}
+
+	return order
+}
+
+// dominators computes the immediate dominator of every node, given in reverse postorder starting with the entry.
+// The entry of the graph is its own immediate dominator.
+func (s *SSA) dominators(order []*GraphNode) {
+	index := make(map[*GraphNode]int, len(order))
+	for i, n := range order {
+		index[n] = i
+	}
+
+	intersect := func(a *GraphNode, b *GraphNode) *GraphNode {
+		for a != b {
+			for index[a] > index[b] {
+				a = s.idom[a]
+			}
+
+			for index[b] > index[a] {
+				b = s.idom[b]
+			}
+		}
+
+		return a
+	}
+
+	s.idom[order[0]] = order[0]
+	for changed := true; changed; {
+		changed = false
+		for _, n := range order[1:] {
+			var idom *GraphNode
+			for _, e := range n.Incoming {
+				if s.idom[e.From] == nil {
+					continue
+				}
+
+				if idom == nil {
+					idom = e.From
+				} else {
+					idom = intersect(e.From, idom)
+				}
+			}
+
+			if s.idom[n] != idom {
+				s.idom[n] = idom
+				changed = true
+			}
+		}
+	}
+}
+
+// frontiers returns the dominance frontier of every node, which are the nodes where the paths it dominates join other paths.
+func (s *SSA) frontiers(order []*GraphNode) map[*GraphNode][]*GraphNode {
+	frontiers := make(map[*GraphNode][]*GraphNode)
+	for _, n := range order {
+		var preds []*GraphNode
+		for _, e := range n.Incoming {
+			if s.idom[e.From] != nil && !containsNode(preds, e.From) {
+				preds = append(preds, e.From)
+			}
+		}
+
+		if len(preds) < 2 {
+			continue
+		}
+
+		for _, p := range preds {
+			for runner := p; runner != s.idom[n]; runner = s.idom[runner] {
+				if !containsNode(frontiers[runner], n) {
+					frontiers[runner] = append(frontiers[runner], n)
+				}
+			}
+		}
+	}
+
+	return frontiers
+}
+
+// containsNode determines if a list of nodes contains a node.
+func containsNode(list []*GraphNode, n *GraphNode) bool {
+	for _, other := range list {
+		if other == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ssaRenamer gives every assignment of a graph its own version, and links every use to the version reaching it.
+type ssaRenamer struct {
+	ssa   *SSA
+	graph *FunctionGraph
+	// stacks holds the values of every variable along the path of dominators being renamed, innermost last.
+	stacks map[Variable][]*Value
+	// versions holds the number of values of every variable.
+	versions map[Variable]int
+	// entries holds the value every variable starts with.
+	entries map[Variable]*Value
+}
+
+// current returns the value a variable holds at the point being renamed.
+func (r *ssaRenamer) current(v Variable) *Value {
+	if stack := r.stacks[v]; len(stack) > 0 {
+		return stack[len(stack)-1]
+	}
+
+	if r.entries[v] == nil {
+		r.entries[v] = &Value{Var: v}
+	}
+
+	return r.entries[v]
+}
+
+// define gives a value the next version of its variable, and makes it the value the variable holds.
+func (r *ssaRenamer) define(v *Value) {
+	r.versions[v.Var]++
+	v.Version = r.versions[v.Var]
+	r.stacks[v.Var] = append(r.stacks[v.Var], v)
+}
+
+// rename renames a node and the nodes it dominates.
+// reached depicts if the node can be reached, which is the only way it can fill in the phi nodes of its children.
+func (r *ssaRenamer) rename(n *GraphNode, children map[*GraphNode][]*GraphNode, reached bool) {
+	var defined []Variable
+	for _, phi := range r.ssa.Phis[n] {
+		r.define(phi.Value)
+		defined = append(defined, phi.Value.Var)
+	}
+
+	// Exceptions may be thrown before any item of the node completes, so they carry the values it starts with.
+	start := make(map[Variable]*Value)
+	for _, e := range n.Edges {
+		if e.Kind == ExceptionEdge {
+			for _, phi := range r.ssa.Phis[e.To] {
+				start[phi.Value.Var] = r.current(phi.Value.Var)
+			}
+		}
+	}
+
+	for _, item := range n.Items {
+		for _, a := range r.ssa.CFG.Accesses(item) {
+			if a.Deferred || !a.Direct() {
+				continue
+			}
+
+			ref := ssaRef{item, a.Id}
+			if !a.Write {
+				r.ssa.uses[ref] = r.current(a.Var)
+				continue
+			}
+
+			v := &Value{Var: a.Var, Item: item, Id: a.Id}
+			r.define(v)
+			r.ssa.defs[ref] = v
+			r.ssa.values[item] = append(r.ssa.values[item], v)
+			defined = append(defined, a.Var)
+		}
+	}
+
+	if reached {
+		for _, e := range n.Edges {
+			for _, phi := range r.ssa.Phis[e.To] {
+				arg := r.current(phi.Value.Var)
+				if e.Kind == ExceptionEdge {
+					arg = start[phi.Value.Var]
+				}
+
+				for i, in := range e.To.Incoming {
+					if in == e {
+						phi.Args[i] = arg
+					}
+				}
+			}
+		}
+	}
+
+	for _, child := range children[n] {
+		r.rename(child, children, reached)
+	}
+
+	for _, v := range defined {
+		r.stacks[v] = r.stacks[v][:len(r.stacks[v])-1]
+	}
+}
+
+// String prints every graph in static single assignment form, in the order the functions are created.
+// Phi nodes are printed at the start of their node, followed by the values every item defines and the values it uses.
+func (s *SSA) String() string {
+	var b strings.Builder
+	for i, g := range s.CFG.Functions {
+		fmt.Fprintf(&b, "graph %d %T\n", i, g.Node)
+		for _, n := range g.Nodes {
+			switch n.Kind {
+			case EntryNode:
+				fmt.Fprintf(&b, "%d entry", n.Id)
+			case ExitNode:
+				fmt.Fprintf(&b, "%d exit", n.Id)
+			default:
+				fmt.Fprintf(&b, "%d block", n.Id)
+			}
+
+			if d := s.Dominator(n); d != nil {
+				fmt.Fprintf(&b, " idom %d", d.Id)
+			}
+
+			b.WriteByte('\n')
+
+			for _, phi := range s.Phis[n] {
+				args := make([]string, len(phi.Args))
+				for j, arg := range phi.Args {
+					args[j] = "-"
+					if arg != nil {
+						args[j] = arg.String()
+					}
+				}
+
+				fmt.Fprintf(&b, "  %s = phi(%s)\n", phi.Value, strings.Join(args, ", "))
+			}
+
+			for _, item := range n.Items {
+				var defs, uses []string
+				for _, v := range s.values[item] {
+					defs = append(defs, v.String())
+				}
+
+				for _, a := range s.CFG.Accesses(item) {
+					if v := s.uses[ssaRef{item, a.Id}]; v != nil && !a.Write && !a.Deferred && a.Direct() {
+						uses = append(uses, v.String())
+					}
+				}
+
+				fmt.Fprintf(&b, "  [%s] <- [%s]\n", strings.Join(defs, " "), strings.Join(uses, " "))
+			}
+		}
+	}
+
+	return b.String()
+}
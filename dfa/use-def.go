@@ -1,8 +1,110 @@
 package dfa
 
-import "github.com/t14raptor/go-fast/ast"
+import (
+	"sort"
+
+	"github.com/t14raptor/go-fast/ast"
+)
 
 type UseDef struct {
-	Usage       *ast.Identifier
+	Usage *ast.Identifier
+	// Path holds the chain of property names read from the usage, if it's the base of a member expression.
+	// "[]" is a computed property that may be any property.
+	Path        []string
 	Definitions []*ScopeDef
+	// Properties holds the definitions of the properties under Path that reach a usage with the ReadRole or CallRole.
+	// The value is read as a whole, so any of its properties may be read through it.
+	Properties []*ScopeDef
+	// Captured depicts if the usage is inside of a closure, using a variable from outside of it.
+	// The definitions of a captured variable include every definition that may run after the closure was created.
+	Captured bool
+	// TDZ depicts if the usage may happen before its let, const or class binding is initialized.
+	TDZ bool
+	// Role is the role the usage plays in the expression it's part of.
+	Role Role
+	// Unresolved depicts if no definition reaches the usage, as it refers to a variable that's neither declared nor a known global.
+	Unresolved bool
+}
+
+// Role is the syntactic role of a variable reference.
+// Identifiers in any other position, such as property names, labels and declared names, aren't references.
+type Role int
+
+const (
+	// ReadRole reads the value of the variable.
+	ReadRole Role = iota
+	// CallRole calls the value of the variable, as the callee of a call or new expression.
+	CallRole
+	// UpdateRole reads the value of the variable before writing it back, as the target of an update expression or compound assignment.
+	UpdateRole
+	// ObjectRole reads the value of the variable to access one of its properties.
+	ObjectRole
+	// PropertyRole reads the property of the variable in Path, rather than the variable itself.
+	// Its definitions are the ones of the property, and the variable is also used with the ObjectRole.
+	PropertyRole
+)
+
+func (r Role) String() string {
+	switch r {
+	case CallRole:
+		return "call"
+	case UpdateRole:
+		return "update"
+	case ObjectRole:
+		return "object"
+	case PropertyRole:
+		return "property"
+	}
+
+	return "read"
+}
+
+// Diagnostic is a problem found in the analyzed code.
+type Diagnostic struct {
+	Usage   *ast.Identifier
+	Message string
+}
+
+// linkUses builds the def-use chains from the use-def chains, once every usage is resolved.
+func (r *rdaContext) linkUses() {
+	r.defUses = make(map[*ScopeDef][]*UseDef)
+	for _, ud := range r.UseDefs {
+		for _, def := range append(ud.Definitions, ud.Properties...) {
+			if def == nil || def == Undefined {
+				continue
+			}
+
+			uses := r.defUses[def]
+			if len(uses) == 0 || uses[len(uses)-1] != ud {
+				r.defUses[def] = append(uses, ud)
+			}
+		}
+	}
+
+	r.defs = make([]*ScopeDef, 0, len(r.defRegistry))
+	for _, def := range r.defRegistry {
+		r.defs = append(r.defs, def)
+	}
+
+	sort.Slice(r.defs, func(i, j int) bool {
+		return r.defs[i].Count < r.defs[j].Count
+	})
+}
+
+// UsesOf returns every usage a definition reaches, in the order they appear in UseDefs.
+func (r *rdaContext) UsesOf(def *ScopeDef) []*UseDef {
+	return r.defUses[def]
+}
+
+// UnusedDefs returns every definition made by the analyzed code that doesn't reach any usage, in the order they're made.
+// Definitions of properties are unused if neither the property nor any object holding it is read afterwards.
+func (r *rdaContext) UnusedDefs() []*ScopeDef {
+	unused := []*ScopeDef{}
+	for _, def := range r.defs {
+		if len(r.defUses[def]) == 0 {
+			unused = append(unused, def)
+		}
+	}
+
+	return unused
 }
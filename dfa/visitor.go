@@ -1,9 +1,8 @@
 package dfa
 
 import (
-	"fmt"
-
 	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/token"
 )
 
 type DfaVisitor struct {
@@ -19,36 +18,50 @@ func (lv *DfaVisitor) VisitArrayPattern(n *ast.ArrayPattern) {
 	n.VisitChildrenWith(lv)
 }
 func (lv *DfaVisitor) VisitArrowFunctionLiteral(n *ast.ArrowFunctionLiteral) {
-	n.VisitChildrenWith(lv)
+	// Expression bodies have no declarations to hoist.
+	var body ast.Statements
+	if b, ok := n.Body.Body.(*ast.BlockStatement); ok {
+		body = b.List
+	}
+
+	lv.visitFunction(n, nil, &n.ParameterList, body, functionEntry{created: lv.Ctx.defCount}, func() {
+		lv.VisitConciseBody(n.Body)
+	})
 }
 
+// VisitAssignExpression evaluates the value of an assignment, and then defines its target.
+// Compound assignments always write, so every assignment replaces the previous definitions of its target.
+// The object and computed key of a property target are evaluated ahead of the value.
 func (lv *DfaVisitor) VisitAssignExpression(n *ast.AssignExpression) {
-	currentScope := lv.Ctx.scopeStack[lv.Ctx.scopeDepth]
-	id := n.Left.Expr.(*ast.Identifier).Name
-
-	foundDepth := 0
-	conditional := false
-
-	if n.Operator.String() != "=" {
-		conditional = true
-	}
-	typ := GlobalScope
-outer:
-	for i := lv.Ctx.scopeDepth; i >= 0; i-- {
-		if lv.Ctx.scopeStack[i].Conditional && i != lv.Ctx.scopeDepth {
-			conditional = true
+	switch left := n.Left.Expr.(type) {
+	case *ast.Identifier:
+		// Compound assignments read the target before the right hand side is evaluated.
+		if n.Operator != token.Assign {
+			lv.reference(left, UpdateRole)
 		}
+		lv.VisitExpression(n.Right)
+
+		typ, foundDepth := lv.Ctx.lookupDef(left.Name)
+		lv.Ctx.addValue(lv.Ctx.scopeStack[lv.Ctx.scopeDepth], left.Name, n.Right, !lv.Ctx.conditionalAssignment(left.Name), typ, foundDepth)
+	case *ast.MemberExpression, *ast.PrivateDotExpression:
+		// Assigning to a property defines the property, and uses the object it belongs to.
+		lv.visitObject(left)
+		if n.Operator != token.Assign {
+			lv.useMember(left)
+		}
+		lv.VisitExpression(n.Right)
 
-		if f, ok := lv.Ctx.scopeStack[i].Definitions[id]; ok {
-			for _, x := range f {
-				typ = x.Typ
-				foundDepth = x.Depth
-				break outer
-			}
+		if base, path, ok := accessPath(left, lv.Ctx.thisName); ok {
+			lv.defineProperty(base.Name, path, n.Right, true)
 		}
+	case *ast.ArrayPattern, *ast.ObjectPattern:
+		// Destructuring assignments define every identifier in the pattern.
+		lv.VisitExpression(n.Right)
+		lv.bindPattern(left, n.Right, true, lv.assignmentDefiner())
+	default:
+		lv.VisitExpression(n.Left)
+		lv.VisitExpression(n.Right)
 	}
-
-	currentScope.AddValue(id, n.Right, !conditional, typ, foundDepth)
 }
 
 func (lv *DfaVisitor) VisitAwaitExpression(n *ast.AwaitExpression) {
@@ -59,43 +72,99 @@ func (lv *DfaVisitor) VisitBadStatement(n *ast.BadStatement) {
 	n.VisitChildrenWith(lv)
 }
 func (lv *DfaVisitor) VisitBinaryExpression(n *ast.BinaryExpression) {
-	n.VisitChildrenWith(lv)
+	if !shortCircuits(n.Operator) {
+		n.VisitChildrenWith(lv)
+		return
+	}
+
+	lv.VisitExpression(n.Left)
+
+	// The right operand only runs if the left operand doesn't decide the result, like an if without an else.
+	rightScope := NewScope(true, false)
+	lv.Ctx.pushScope(rightScope)
+	lv.VisitExpression(n.Right)
+	lv.Ctx.popScope()
+
+	lv.Ctx.joinPaths([]*Scope{rightScope, lv.Ctx.scopeStack[lv.Ctx.scopeDepth]})
 }
-func (lv *DfaVisitor) VisitBindingTarget(n *ast.BindingTarget) {
 
-	n.VisitChildrenWith(lv)
+// shortCircuits determines if an operator may skip evaluating its right operand.
+func shortCircuits(op token.Token) bool {
+	return op == token.LogicalAnd || op == token.LogicalOr || op == token.Coalesce
+}
+func (lv *DfaVisitor) VisitBindingTarget(n *ast.BindingTarget) {
+	// Binding targets declare names rather than referencing them, and are bound along with their declaration.
 }
 func (lv *DfaVisitor) VisitBlockStatement(n *ast.BlockStatement) {
+	// Every block has its own lexical scope, which the bindings declared in it expire with.
+	blockScope := NewScope(false, false)
+	lv.Ctx.pushScope(blockScope)
+	lv.declareLexical(n.List)
 
 	n.VisitChildrenWith(lv)
+
+	lv.Ctx.popScope()
+	lv.Ctx.mergeDown(lv.Ctx.scopeDepth+1, blockScope, false)
+}
+
+// declareLexical declares the let, const and class bindings of a statement list in the temporal dead zone.
+// Using a binding before its declaration runs is then reported, instead of resolving to an outer definition.
+// stmts is the statement list the bindings are declared in.
+func (lv *DfaVisitor) declareLexical(stmts ast.Statements) {
+	currentScope := lv.Ctx.scopeStack[lv.Ctx.scopeDepth]
+	depth := lv.Ctx.lexicalDepth()
+
+	if currentScope.lexicals == nil {
+		currentScope.lexicals = make(map[string]bool)
+	}
+
+	for _, id := range lexicalDeclarations(stmts) {
+		currentScope.Definitions[id.Name] = []*ScopeDef{lv.Ctx.tdzDef(id, depth)}
+		currentScope.lexicals[id.Name] = true
+	}
 }
 func (lv *DfaVisitor) VisitBooleanLiteral(n *ast.BooleanLiteral) {
 
 	n.VisitChildrenWith(lv)
 }
 func (lv *DfaVisitor) VisitBreakStatement(n *ast.BreakStatement) {
+	label := ""
+	if n.Label != nil {
+		label = n.Label.Name
+	}
 
-	n.VisitChildrenWith(lv)
+	lv.Ctx.jump(label, false)
 }
 func (lv *DfaVisitor) VisitCallExpression(n *ast.CallExpression) {
-
-	n.VisitChildrenWith(lv)
+	lv.visitReference(n.Callee, CallRole)
+	lv.VisitExpressions(&n.ArgumentList)
 }
-func (lv *DfaVisitor) VisitCaseStatement(n *ast.CaseStatement) {
 
-	n.VisitChildrenWith(lv)
+// VisitCaseStatement expects the case scope to already be pushed by VisitSwitchStatement.
+func (lv *DfaVisitor) VisitCaseStatement(n *ast.CaseStatement) {
+	lv.VisitStatements(&n.Consequent)
 }
 func (lv *DfaVisitor) VisitCaseStatements(n *ast.CaseStatements) {
 
 	n.VisitChildrenWith(lv)
 }
+
+// VisitCatchStatement expects the catch scope to already be pushed by VisitTryStatement.
 func (lv *DfaVisitor) VisitCatchStatement(n *ast.CatchStatement) {
+	if n.Parameter != nil {
+		lv.bindCatchParameter(n)
+	}
 
-	n.VisitChildrenWith(lv)
+	lv.VisitBlockStatement(n.Body)
 }
-func (lv *DfaVisitor) VisitClassDeclaration(n *ast.ClassDeclaration) {
 
-	n.VisitChildrenWith(lv)
+// bindCatchParameter binds the parameter of a catch clause.
+func (lv *DfaVisitor) bindCatchParameter(n *ast.CatchStatement) {
+	// The caught value has no expression of its own, so the parameter is used as its value.
+	lv.bindPattern(n.Parameter.Target, nil, true, selfValued(lv.declarationDefiner("let")))
+}
+func (lv *DfaVisitor) VisitClassDeclaration(n *ast.ClassDeclaration) {
+	lv.visitClass(n.Class, true)
 }
 func (lv *DfaVisitor) VisitClassElement(n *ast.ClassElement) {
 
@@ -106,12 +175,13 @@ func (lv *DfaVisitor) VisitClassElements(n *ast.ClassElements) {
 	n.VisitChildrenWith(lv)
 }
 func (lv *DfaVisitor) VisitClassLiteral(n *ast.ClassLiteral) {
-
-	n.VisitChildrenWith(lv)
+	lv.visitClass(n, false)
 }
-func (lv *DfaVisitor) VisitClassStaticBlock(n *ast.ClassStaticBlock) {
 
-	n.VisitChildrenWith(lv)
+// VisitClassStaticBlock expects this to already refer to its class.
+func (lv *DfaVisitor) VisitClassStaticBlock(n *ast.ClassStaticBlock) {
+	// Static blocks run as soon as the class is defined, so their definitions fall through into the code following the class.
+	lv.VisitBlockStatement(n.Block)
 }
 func (lv *DfaVisitor) VisitComputedProperty(n *ast.ComputedProperty) {
 
@@ -122,20 +192,47 @@ func (lv *DfaVisitor) VisitConciseBody(n *ast.ConciseBody) {
 	n.VisitChildrenWith(lv)
 }
 func (lv *DfaVisitor) VisitConditionalExpression(n *ast.ConditionalExpression) {
+	lv.VisitExpression(n.Test)
 
-	n.VisitChildrenWith(lv)
+	// Exactly one of the branches runs, like an if with an else.
+	consequentScope := NewScope(true, false)
+	lv.Ctx.pushScope(consequentScope)
+	lv.VisitExpression(n.Consequent)
+	lv.Ctx.popScope()
+
+	alternateScope := NewScope(true, false)
+	lv.Ctx.pushScope(alternateScope)
+	lv.VisitExpression(n.Alternate)
+	lv.Ctx.popScope()
+
+	lv.Ctx.joinPaths([]*Scope{consequentScope, alternateScope})
 }
 func (lv *DfaVisitor) VisitContinueStatement(n *ast.ContinueStatement) {
+	label := ""
+	if n.Label != nil {
+		label = n.Label.Name
+	}
 
-	n.VisitChildrenWith(lv)
+	lv.Ctx.jump(label, true)
 }
 func (lv *DfaVisitor) VisitDebuggerStatement(n *ast.DebuggerStatement) {
 
 	n.VisitChildrenWith(lv)
 }
 func (lv *DfaVisitor) VisitDoWhileStatement(n *ast.DoWhileStatement) {
+	// The body always runs at least once, so the loop scope isn't conditional.
+	doScope, _, target := lv.visitLoop(n, lv.Ctx.takeLabels(), false, func(target *jumpTarget) {
+		lv.VisitStatement(n.Body)
 
-	n.VisitChildrenWith(lv)
+		// Continue statements jump to the test.
+		currentScope := lv.Ctx.scopeStack[lv.Ctx.scopeDepth]
+		lv.Ctx.joinPaths(append([]*Scope{currentScope}, target.continues...))
+
+		lv.VisitExpression(n.Test)
+	})
+
+	// The loop exits after its test fails, or by breaking out of it.
+	lv.Ctx.joinPaths(append([]*Scope{doScope}, target.breaks...))
 }
 func (lv *DfaVisitor) VisitEmptyStatement(n *ast.EmptyStatement) {
 
@@ -143,25 +240,6 @@ func (lv *DfaVisitor) VisitEmptyStatement(n *ast.EmptyStatement) {
 }
 
 func (lv *DfaVisitor) VisitExpression(n *ast.Expression) {
-	if u, ok := n.Expr.(*ast.UpdateExpression); ok {
-		if id, ok := u.Operand.Expr.(*ast.Identifier); ok {
-			foundDepth := 0
-			typ := GlobalScope
-		outer:
-			for i := lv.Ctx.scopeDepth; i >= 0; i-- {
-				if f, ok := lv.Ctx.scopeStack[i].Definitions[id.Name]; ok {
-					for _, x := range f {
-						typ = x.Typ
-						foundDepth = x.Depth
-						break outer
-					}
-				}
-			}
-
-			lv.Ctx.scopeStack[lv.Ctx.scopeDepth].AddValue(id.Name, n, false, typ, foundDepth)
-			return
-		}
-	}
 	n.VisitChildrenWith(lv)
 }
 
@@ -173,13 +251,22 @@ func (lv *DfaVisitor) VisitExpressions(n *ast.Expressions) {
 
 	n.VisitChildrenWith(lv)
 }
+
+// VisitFieldDefinition expects this to already refer to the object the field is defined on.
+// Computed keys are evaluated along with the class, so only the initializer is visited.
 func (lv *DfaVisitor) VisitFieldDefinition(n *ast.FieldDefinition) {
+	if n.Initializer != nil {
+		lv.VisitExpression(n.Initializer)
+	}
 
-	n.VisitChildrenWith(lv)
+	if lv.Ctx.thisName == "" {
+		return
+	}
+
+	lv.defineProperty(lv.Ctx.thisName, []string{elementName(n.Key)}, n.Initializer, true)
 }
 func (lv *DfaVisitor) VisitForInStatement(n *ast.ForInStatement) {
-
-	n.VisitChildrenWith(lv)
+	lv.visitForEach(n, n.Into, n.Source, n.Body)
 }
 func (lv *DfaVisitor) VisitForInto(n *ast.ForInto) {
 
@@ -190,202 +277,464 @@ func (lv *DfaVisitor) VisitForLoopInitializer(n *ast.ForLoopInitializer) {
 	n.VisitChildrenWith(lv)
 }
 func (lv *DfaVisitor) VisitForOfStatement(n *ast.ForOfStatement) {
-
-	n.VisitChildrenWith(lv)
+	lv.visitForEach(n, n.Into, n.Source, n.Body)
 }
 
 func (lv *DfaVisitor) VisitForStatement(n *ast.ForStatement) {
-	// Header scope
+	labels := lv.Ctx.takeLabels()
+
+	// Header scope, which holds the bindings declared by the initializer.
 	headerScope := NewScope(false, false)
 	lv.Ctx.pushScope(headerScope)
 
 	if n.Initializer != nil {
+		// let and const declarations in the initializer are scoped to the loop.
+		if d, ok := n.Initializer.Initializer.(*ast.VariableDeclaration); ok {
+			lv.declareLexical(ast.Statements{{Stmt: d}})
+		}
+
 		lv.VisitForLoopInitializer(n.Initializer)
 	}
 
-	if n.Update.Expr != nil {
+	// determines if there's a test or not in the for loop.
+	isConditional := n.Test.Expr != nil
+
+	_, backEdge, target := lv.visitLoop(n, labels, isConditional, func(target *jumpTarget) {
+		if isConditional {
+			lv.VisitExpression(n.Test)
+		}
+
+		if n.Update.Expr == nil {
+			lv.VisitStatement(n.Body)
+			return
+		}
+
+		// The update runs after the body, but its definitions are counted in the order they're written, ahead of the body's.
+		updateCount := lv.Ctx.defCount
+		lv.Ctx.defCount += lv.countDefinitions(func() {
+			lv.VisitExpression(n.Update)
+		})
+
+		lv.VisitStatement(n.Body)
+
+		// Continue statements jump to the update, which runs at the end of every iteration before the test is evaluated again.
+		currentScope := lv.Ctx.scopeStack[lv.Ctx.scopeDepth]
+		lv.Ctx.joinPaths(append([]*Scope{currentScope}, target.continues...))
+		target.continues = nil
+
+		bodyCount := lv.Ctx.defCount
+		lv.Ctx.defCount = updateCount
 		lv.VisitExpression(n.Update)
+		lv.Ctx.defCount = bodyCount
+	})
+
+	// The loop exits when its test fails, or by breaking out of it.
+	exits := target.breaks
+	if isConditional {
+		exits = append([]*Scope{headerScope, backEdge}, exits...)
 	}
 
-	// determines if there's a test or not in the for loop.
-	isConditional := false
-	if n.Test.Expr != nil {
-		lv.VisitExpression(n.Test)
-		isConditional = true
+	lv.Ctx.joinPaths(exits)
+	lv.Ctx.popScope()
+
+	lv.Ctx.mergeDown(lv.Ctx.scopeDepth+1, headerScope, false)
+}
+
+// visitLoop visits a loop until the definitions carried along its back edge stop changing.
+// Only the outermost loop is visited repeatedly, and the loops nested in it take a single pass every time it does,
+// carrying their back edges over to the next one. The outermost loop stops once none of the back edges change.
+// loop is the loop statement.
+// labels are the labels of the loop statement.
+// cond depicts if the loop scope is a conditional scope.
+// visit is called once per pass with the loop scope pushed, and should visit the loop's test and body.
+// The loop scope, back edge and jump target of the final pass are returned so the loop's exits can be joined.
+func (lv *DfaVisitor) visitLoop(loop ast.VisitableNode, labels []string, cond bool, visit func(target *jumpTarget)) (*Scope, *Scope, *jumpTarget) {
+	outermost := len(lv.Ctx.loops) == 0
+	if outermost {
+		lv.Ctx.backEdges = make(map[ast.VisitableNode]*Scope)
+	}
+
+	// The back edge is unreachable until the end of the body or a continue statement reaches it.
+	backEdge, ok := lv.Ctx.backEdges[loop]
+	if !ok {
+		backEdge = NewScope(false, false)
+		backEdge.Unreachable = true
+		lv.Ctx.backEdges[loop] = backEdge
 	}
 
+	// Every pass revisits the same code, so definition counts and usages are rewound each time.
+	start := lv.Ctx.mark()
+
+	lv.Ctx.loops = append(lv.Ctx.loops, loopEntry{depth: lv.Ctx.scopeDepth, count: start.count})
+	defer func() {
+		lv.Ctx.loops = lv.Ctx.loops[:len(lv.Ctx.loops)-1]
+	}()
+
+	for {
+		lv.Ctx.rewind(start)
+		if outermost {
+			lv.Ctx.loopChanged = false
+		}
+
+		target := lv.Ctx.pushTarget(labels, true, true)
+		loopScope := NewScope(cond, false)
+		lv.Ctx.pushScope(loopScope)
+		loopScope.MergeSameDepth(backEdge)
+
+		visit(target)
+
+		lv.Ctx.popScope()
+		lv.Ctx.popTarget()
+
+		// Both the end of the body and continue statements lead back to the start of the loop.
+		for _, s := range append([]*Scope{loopScope}, target.continues...) {
+			if s.Unreachable {
+				continue
+			}
+
+			backEdge.Unreachable = false
+			if lv.Ctx.mergeLive(backEdge, s) {
+				lv.Ctx.loopChanged = true
+			}
+		}
+
+		if !outermost || !lv.Ctx.loopChanged {
+			return loopScope, backEdge, target
+		}
+	}
+}
+
+// countDefinitions returns the number of definitions visit makes, discarding them along with everything else it records.
+func (lv *DfaVisitor) countDefinitions(visit func()) int64 {
+	start := lv.Ctx.mark()
+
+	lv.Ctx.pushScope(NewScope(false, false))
+	visit()
 	lv.Ctx.popScope()
 
-	// Create body scope and merge variables from header scope.
-	forScope := NewScope(isConditional, false)
-	lv.Ctx.pushScope(forScope)
-	forScope.MergeSameDepth(headerScope)
+	count := lv.Ctx.defCount - start.count
+	lv.Ctx.rewind(start)
+	return count
+}
 
-	lv.VisitStatement(n.Body)
+// visitForEach visits a for-in or for-of loop.
+// The source is evaluated once before the loop, and the loop binding is redefined at the start of every iteration.
+func (lv *DfaVisitor) visitForEach(loop ast.VisitableNode, into *ast.ForInto, source *ast.Expression, body *ast.Statement) {
+	labels := lv.Ctx.takeLabels()
 
+	// let and const bindings of the loop are in their temporal dead zone while the source is evaluated.
+	sourceScope := NewScope(false, false)
+	lv.Ctx.pushScope(sourceScope)
+	if d, ok := into.Into.(*ast.VariableDeclaration); ok {
+		lv.declareLexical(ast.Statements{{Stmt: d}})
+	}
+
+	lv.VisitExpression(source)
 	lv.Ctx.popScope()
+	lv.Ctx.mergeDown(lv.Ctx.scopeDepth+1, sourceScope, false)
 
-	if isConditional {
-		forScope.AddUndefined(lv.Ctx.scopeStack[lv.Ctx.scopeDepth])
+	// The body runs once for every element in the source, which may be none.
+	_, backEdge, target := lv.visitLoop(loop, labels, true, func(*jumpTarget) {
+		lv.defineForInto(into, source)
+		lv.VisitStatement(body)
+	})
+
+	// The loop exits once the source is exhausted, or by breaking out of it.
+	currentScope := lv.Ctx.scopeStack[lv.Ctx.scopeDepth]
+	lv.Ctx.joinPaths(append([]*Scope{currentScope, backEdge}, target.breaks...))
+}
+
+// defineForInto defines the binding of a for-in or for-of loop for a single iteration.
+// source is the expression being iterated over, which is used as the value of the binding.
+func (lv *DfaVisitor) defineForInto(n *ast.ForInto, source *ast.Expression) {
+	var target ast.Expr
+	var define defineFunc
+
+	switch into := n.Into.(type) {
+	case *ast.VariableDeclaration:
+		target = into.List[0].Target.Target
+		define = lv.declarationDefiner(into.Token.String())
+	case *ast.Expression:
+		target = into.Expr
+		define = lv.assignmentDefiner()
+	}
+
+	// Elements of the source can't be resolved, so everything bound without a default takes the source as its value.
+	lv.bindPattern(target, nil, true, func(id *ast.Identifier, v *ast.Expression, overwrite bool) {
+		if v == nil {
+			v = source
+		}
+
+		define(id, v, overwrite)
+	})
+}
+
+// declarationDefiner returns a defineFunc declaring identifiers in the current scope.
+// kind is the keyword of the declaration, where var declarations belong to the function scope and any other kind to the enclosing block.
+func (lv *DfaVisitor) declarationDefiner(kind string) defineFunc {
+	currentScope := lv.Ctx.scopeStack[lv.Ctx.scopeDepth]
+
+	return func(id *ast.Identifier, v *ast.Expression, overwrite bool) {
+		if kind == "var" {
+			lv.Ctx.addValue(currentScope, id.Name, v, overwrite, FunctionScope, lv.Ctx.functionScopeDepth)
+			return
+		}
+
+		lv.Ctx.addValue(currentScope, id.Name, v, overwrite, BlockScope, lv.Ctx.lexicalDepth())
+	}
+}
+
+// assignmentDefiner returns a defineFunc assigning to identifiers from the current scope, wherever they're declared.
+func (lv *DfaVisitor) assignmentDefiner() defineFunc {
+	currentScope := lv.Ctx.scopeStack[lv.Ctx.scopeDepth]
+
+	return func(id *ast.Identifier, v *ast.Expression, overwrite bool) {
+		typ, depth := lv.Ctx.lookupDef(id.Name)
+		lv.Ctx.addValue(currentScope, id.Name, v, overwrite, typ, depth)
 	}
+}
 
-	lv.Ctx.mergeDown(lv.Ctx.scopeDepth+1, forScope, isConditional)
+// selfValued wraps a defineFunc so identifiers with an unknown value use themselves as their value, as parameters do.
+func selfValued(define defineFunc) defineFunc {
+	return func(id *ast.Identifier, v *ast.Expression, overwrite bool) {
+		if v == nil {
+			v = &ast.Expression{Expr: id}
+		}
+
+		define(id, v, overwrite)
+	}
 }
 
 func (lv *DfaVisitor) VisitFunctionDeclaration(n *ast.FunctionDeclaration) {
-	n.VisitChildrenWith(lv)
+	f := n.Function
+
+	// Function declarations hoisted to the top of their function scope are already defined,
+	// and exist from the start of it.
+	entry, hoisted := lv.Ctx.hoisted[n]
+	if !hoisted {
+		entry = functionEntry{created: lv.Ctx.defCount}
+		lv.defineFunction(n)
+	}
+
+	lv.visitFunction(f, nil, &f.ParameterList, f.Body.List, entry, func() {
+		lv.VisitBlockStatement(f.Body)
+	})
+}
+
+// defineFunction binds the name of a function declaration in the enclosing function scope.
+func (lv *DfaVisitor) defineFunction(n *ast.FunctionDeclaration) {
+	f := n.Function
+	if f.Name != nil {
+		lv.Ctx.addValue(lv.Ctx.scopeStack[lv.Ctx.scopeDepth], f.Name.Name, &ast.Expression{Expr: f}, true, FunctionScope, lv.Ctx.functionScopeDepth)
+	}
 }
 
 func (lv *DfaVisitor) VisitFunctionLiteral(n *ast.FunctionLiteral) {
-	n.VisitChildrenWith(lv)
+	// The name of a function expression is only bound inside of the function itself.
+	lv.visitFunction(n, n.Name, &n.ParameterList, n.Body.List, functionEntry{created: lv.Ctx.defCount}, func() {
+		lv.VisitBlockStatement(n.Body)
+	})
 }
 
-func (lv *DfaVisitor) VisitIdentifier(n *ast.Identifier) {
-	if n.Name != "log" {
-		defs := lv.Ctx.scopeStack[lv.Ctx.scopeDepth].Definitions[n.Name]
+// hoistDeclarations defines the hoisted declarations of a function body at the top of the current function scope.
+// Variables declared with var start out undefined, and function declarations are defined as the function itself.
+// body is the list of statements in the function body.
+func (lv *DfaVisitor) hoistDeclarations(body ast.Statements) {
+	currentScope := lv.Ctx.scopeStack[lv.Ctx.scopeDepth]
+
+	h := newHoistVisitor()
+	body.VisitWith(h)
 
-		ud := &UseDef{
-			Usage:       n,
-			Definitions: defs,
+	if currentScope.vars == nil {
+		currentScope.vars = make(map[string]bool)
+	}
+
+	for _, id := range h.vars {
+		currentScope.vars[id] = true
+
+		// Redeclaring a parameter or the function's own name with var keeps its value.
+		if lv.Ctx.definedInScope(currentScope, id) {
+			continue
 		}
 
-		lv.Ctx.UseDefs = append(lv.Ctx.UseDefs, ud)
+		currentScope.Definitions[id] = []*ScopeDef{Undefined}
+	}
+
+	var funcs []*ast.FunctionDeclaration
+	for i := range body {
+		if f, ok := body[i].Stmt.(*ast.FunctionDeclaration); ok {
+			funcs = append(funcs, f)
+		}
+	}
+
+	if len(funcs) == 0 {
+		return
+	}
+
+	created := lv.Ctx.defCount
+	for _, f := range funcs {
+		lv.defineFunction(f)
+	}
+
+	// Hoisted functions may be called before anything else in the function scope runs.
+	entry := currentScope.Copy()
+	for _, f := range funcs {
+		lv.Ctx.hoisted[f] = functionEntry{created: created, entry: entry}
 	}
-	n.VisitChildrenWith(lv)
 }
 
-func (lv *DfaVisitor) VisitIfStatement(n *ast.IfStatement) {
-	ifScope := NewScope(true, false)
+// visitFunction visits a function in its own function scope, which is discarded afterwards.
+// fn is the node the function is created by, which is its graph in the control flow graph of the program.
+// name is the name bound inside of the function, if any.
+// params is the parameter list of the function.
+// body is the list of statements in the function body, which declarations are hoisted from.
+// entry describes when the function was created.
+// visit should visit the body of the function.
+func (lv *DfaVisitor) visitFunction(fn ast.VisitableNode, name *ast.Identifier, params *ast.ParameterList, body ast.Statements, entry functionEntry, visit func()) {
+	// Break, continue and throw statements can't leave the function they're in,
+	// so the jump targets and handlers of the enclosing function are set aside.
+	handlers, targets, finallies, labels := lv.Ctx.handlers, lv.Ctx.targets, lv.Ctx.finallies, lv.Ctx.labels
+	lv.Ctx.handlers, lv.Ctx.targets, lv.Ctx.finallies, lv.Ctx.labels = nil, nil, nil, nil
+
+	functionScope := NewScope(false, true)
+	lv.Ctx.pushScope(functionScope)
+
+	// The body runs whenever the function is called, even if its declaration can't be reached.
+	functionScope.Unreachable = false
+
+	// Every function except arrow functions binds the arguments it's called with, which parameters and declarations may shadow.
+	if _, arrow := fn.(*ast.ArrowFunctionLiteral); !arrow {
+		functionScope.Definitions["arguments"] = []*ScopeDef{lv.Ctx.argumentsDef(fn, lv.Ctx.scopeDepth)}
+	}
 
-	lv.Ctx.pushScope(ifScope)
-	lv.VisitExpression(n.Test)
-	lv.VisitStatement(n.Consequent)
+	// Anonymous function expressions have a name with no identifier.
+	if name != nil && name.Name != "" {
+		lv.bindFunctionName(name)
+	}
+
+	lv.defineParameters(params)
+	lv.hoistDeclarations(body)
+
+	entry.depth = lv.Ctx.scopeDepth
+	lv.Ctx.functions = append(lv.Ctx.functions, entry)
+	visit()
+	lv.Ctx.functions = lv.Ctx.functions[:len(lv.Ctx.functions)-1]
+
+	lv.Ctx.closures = append(lv.Ctx.closures, closureRange{depth: lv.Ctx.scopeDepth, start: functionScope.start, end: lv.Ctx.defCount})
 	lv.Ctx.popScope()
+	lv.Ctx.handlers, lv.Ctx.targets, lv.Ctx.finallies, lv.Ctx.labels = handlers, targets, finallies, labels
+}
 
-	if n.Alternate != nil {
-		var elseScope *Scope
-		var elifScopes []*Scope
-		x := n.Alternate
+// bindFunctionName binds the name of a function expression inside of the function itself.
+func (lv *DfaVisitor) bindFunctionName(name *ast.Identifier) {
+	lv.Ctx.addValue(lv.Ctx.scopeStack[lv.Ctx.scopeDepth], name.Name, &ast.Expression{Expr: name}, true, FunctionScope, lv.Ctx.functionScopeDepth)
+}
 
-		// Visit all the blocks.
-		for x != nil {
-			if elif, ok := x.Stmt.(*ast.IfStatement); ok {
-				elifScope := NewScope(true, false)
-				elifScopes = append(elifScopes, elifScope)
+// defineParameters defines the parameters of a function in the current function scope.
+// Default values are visited before the parameter they belong to is defined.
+// params is the parameter list of the function.
+func (lv *DfaVisitor) defineParameters(params *ast.ParameterList) {
+	for i := range params.List {
+		lv.defineParameter(&params.List[i])
+	}
 
-				lv.Ctx.pushScope(elifScope)
-				lv.VisitExpression(elif.Test)
-				lv.VisitStatement(elif.Consequent)
-				lv.Ctx.popScope()
+	if params.Rest != nil {
+		lv.defineRest(params)
+	}
+}
 
-				x = elif.Alternate
-			} else {
-				elseScope = NewScope(true, false)
+// defineParameter visits the default value of a parameter, and then defines it.
+func (lv *DfaVisitor) defineParameter(p *ast.VariableDeclarator) {
+	if p.Initializer != nil {
+		lv.VisitExpression(p.Initializer)
+	}
 
-				lv.Ctx.pushScope(elseScope)
-				lv.VisitStatement(x)
-				lv.Ctx.popScope()
+	// Arguments have no expression of their own, so each parameter is used as its value.
+	lv.bindPattern(p.Target.Target, nil, true, selfValued(lv.declarationDefiner("var")))
+}
 
-				break
-			}
-		}
+// defineRest defines the rest parameter of a parameter list.
+func (lv *DfaVisitor) defineRest(params *ast.ParameterList) {
+	lv.bindPattern(params.Rest, nil, true, selfValued(lv.declarationDefiner("var")))
+}
 
-		if elseScope != nil {
-			// holds a list of values that exist in the else scope.
-			inElseScope := make([]string, len(elseScope.Definitions))
-			counter := 0
-			for id := range elseScope.Definitions {
-				elseDefs := lv.Ctx.findNotExpiring(elseScope, id, true)
-
-				// No original definitions in else statement.
-				if len(elseDefs) == 0 {
-					continue
-				}
-				inElseScope[counter] = id
-				counter++
-			}
+// VisitIdentifier expects to only be reached by identifiers in a reference position.
+// Property names, labels and declared names are never visited as identifiers.
+func (lv *DfaVisitor) VisitIdentifier(n *ast.Identifier) {
+	lv.reference(n, ReadRole)
+}
 
-			// Go through all values that exist in the else scope.
-			for _, id := range inElseScope {
-				elseDefs := lv.Ctx.findNotExpiring(elseScope, id, true)
-				inAll := true
-
-				ifDefs := lv.Ctx.findNotExpiring(ifScope, id, true)
-				ifScope.Definitions[id] = ifDefs
-				if len(ifDefs) == 0 {
-					inAll = false
-				}
-
-				for _, elif := range elifScopes {
-					elifDefs := lv.Ctx.findNotExpiring(elif, id, true)
-					if len(elifDefs) == 0 {
-						inAll = false
-					} else {
-						ifScope.MergeDefs(elifDefs, id)
-					}
-				}
-
-				ifScope.MergeDefs(elseDefs, id)
-
-				if inAll {
-					lv.Ctx.scopeStack[lv.Ctx.scopeDepth].Definitions[id] = ifScope.Definitions[id]
-				} else {
-					if _, ok := lv.Ctx.scopeStack[lv.Ctx.scopeDepth].Definitions[id]; ok {
-						lv.Ctx.scopeStack[lv.Ctx.scopeDepth].MergeDefs(ifScope.Definitions[id], id)
-					} else {
-						lv.Ctx.scopeStack[lv.Ctx.scopeDepth].Definitions[id] = append(ifScope.Definitions[id], Undefined)
-					}
-				}
+// reference records a usage of a variable.
+// n is the identifier referencing the variable, and role is the role it plays in the expression it's part of.
+func (lv *DfaVisitor) reference(n *ast.Identifier, role Role) {
+	if lv.Ctx.Ignored[n.Name] {
+		return
+	}
 
-			}
+	currentScope := lv.Ctx.scopeStack[lv.Ctx.scopeDepth]
 
-			// holds list of identifers that are defined from within the if and elif statements, but don't exist in else.
-			outElseScope := []string{}
-			// cloud the if scope with all the variable declarations from the elifs and only propogate identifiers that don't exist in the else scope.
-			// append downwards.
-			for _, elif := range elifScopes {
-			def_outer:
-				for id, defs := range elif.Definitions {
-					for _, i := range inElseScope {
-						if i == id {
-							// Variable exists in else scope, so it was handled before.
-							continue def_outer
-						}
-					}
-
-					defs = lv.Ctx.findNotExpiring(elif, id, true)
-
-					if len(defs) == 0 {
-						continue
-					}
-
-					if _, ok := ifScope.Definitions[id]; !ok {
-						ifScope.Definitions[id] = defs
-						outElseScope = append(outElseScope, id)
-					} else {
-						ifScope.MergeDefs(defs, id)
-					}
-				}
-			}
+	ud := &UseDef{
+		Usage:       n,
+		Definitions: currentScope.Definitions[n.Name],
+		Role:        role,
+	}
 
-			// merge remaining values down.
-			for _, id := range outElseScope {
-				lv.Ctx.scopeStack[lv.Ctx.scopeDepth].MergeDefs(ifScope.Definitions[id], id)
-			}
+	if readsWhole(role) {
+		ud.Properties = propertyDefs(currentScope, n.Name)
+	}
 
-		} else {
-			// Because no else, merge else if defs with if defs and merge down.
-			for _, elif := range elifScopes {
-				ifScope.MergeSameDepth(elif)
-			}
+	lv.Ctx.UseDefs = append(lv.Ctx.UseDefs, ud)
+	lv.Ctx.captureUse(ud, n.Name)
+	lv.Ctx.checkTDZ(ud)
+}
+
+// visitReference visits an expression that plays a role other than being read, such as a callee.
+// Identifiers are recorded with the role, and any other expression is visited as usual, where properties play the PropertyRole.
+func (lv *DfaVisitor) visitReference(e *ast.Expression, role Role) {
+	if id, ok := e.Expr.(*ast.Identifier); ok {
+		lv.reference(id, role)
+		return
+	}
+
+	lv.VisitExpression(e)
+}
+
+func (lv *DfaVisitor) VisitIfStatement(n *ast.IfStatement) {
+	// Holds every path leading out of the if statement.
+	var paths []*Scope
+
+	for x := n; x != nil; {
+		ifScope := NewScope(true, false)
+
+		lv.Ctx.pushScope(ifScope)
+		lv.VisitExpression(x.Test)
+		lv.VisitStatement(x.Consequent)
+		lv.Ctx.popScope()
+
+		paths = append(paths, ifScope)
+
+		if x.Alternate == nil {
+			// Because no else, none of the blocks may run.
+			paths = append(paths, lv.Ctx.scopeStack[lv.Ctx.scopeDepth])
+			break
+		}
 
-			ifScope.AddUndefined(lv.Ctx.scopeStack[lv.Ctx.scopeDepth])
-			lv.Ctx.mergeDown(lv.Ctx.scopeDepth+1, ifScope, true)
+		if elif, ok := x.Alternate.Stmt.(*ast.IfStatement); ok {
+			x = elif
+			continue
 		}
-	} else {
-		ifScope.AddUndefined(lv.Ctx.scopeStack[lv.Ctx.scopeDepth])
-		lv.Ctx.mergeDown(lv.Ctx.scopeDepth+1, ifScope, true)
+
+		elseScope := NewScope(true, false)
+
+		lv.Ctx.pushScope(elseScope)
+		lv.VisitStatement(x.Alternate)
+		lv.Ctx.popScope()
+
+		paths = append(paths, elseScope)
+		break
 	}
+
+	lv.Ctx.joinPaths(paths)
 }
 
 func (lv *DfaVisitor) VisitInvalidExpression(n *ast.InvalidExpression) {
@@ -393,28 +742,65 @@ func (lv *DfaVisitor) VisitInvalidExpression(n *ast.InvalidExpression) {
 	n.VisitChildrenWith(lv)
 }
 func (lv *DfaVisitor) VisitLabelledStatement(n *ast.LabelledStatement) {
+	lv.Ctx.labels = append(lv.Ctx.labels, n.Label.Name)
+
+	switch n.Statement.Stmt.(type) {
+	case *ast.ForStatement, *ast.ForInStatement, *ast.ForOfStatement, *ast.WhileStatement,
+		*ast.DoWhileStatement, *ast.SwitchStatement, *ast.LabelledStatement:
+		// The label is attached to the statement's own jump target.
+		lv.VisitStatement(n.Statement)
+		return
+	}
 
-	n.VisitChildrenWith(lv)
+	target := lv.Ctx.pushTarget(lv.Ctx.takeLabels(), false, false)
+	labelScope := NewScope(false, false)
+	lv.Ctx.pushScope(labelScope)
+
+	lv.VisitStatement(n.Statement)
+
+	// Breaking out of the statement continues after it.
+	lv.Ctx.joinPaths(append([]*Scope{labelScope}, target.breaks...))
+	lv.Ctx.popScope()
+	lv.Ctx.popTarget()
+
+	lv.Ctx.mergeDown(lv.Ctx.scopeDepth+1, labelScope, false)
 }
 func (lv *DfaVisitor) VisitMemberExpression(n *ast.MemberExpression) {
-
-	n.VisitChildrenWith(lv)
+	// Property names aren't identifiers in scope, so only computed keys are uses.
+	lv.visitObject(n)
+	lv.useMember(n)
 }
 func (lv *DfaVisitor) VisitMemberProperty(n *ast.MemberProperty) {
 
 	n.VisitChildrenWith(lv)
 }
 func (lv *DfaVisitor) VisitMetaProperty(n *ast.MetaProperty) {
-
-	n.VisitChildrenWith(lv)
+	// Meta properties such as new.target are keywords, not references.
 }
+
+// VisitMethodDefinition expects this to already refer to the class for static methods.
+// Instance methods start with the properties of this defined by the constructor.
 func (lv *DfaVisitor) VisitMethodDefinition(n *ast.MethodDefinition) {
+	f := n.Body
 
-	n.VisitChildrenWith(lv)
+	thisName := lv.Ctx.thisName
+	if !n.Static {
+		lv.Ctx.thisName = "this"
+	}
+
+	lv.visitFunction(f, nil, &f.ParameterList, f.Body.List, functionEntry{created: lv.Ctx.defCount}, func() {
+		if !n.Static && lv.Ctx.instance != nil {
+			copyProperties(lv.Ctx.scopeStack[lv.Ctx.scopeDepth], lv.Ctx.instance, "this")
+		}
+
+		lv.VisitBlockStatement(f.Body)
+	})
+
+	lv.Ctx.thisName = thisName
 }
 func (lv *DfaVisitor) VisitNewExpression(n *ast.NewExpression) {
-
-	n.VisitChildrenWith(lv)
+	lv.visitReference(n.Callee, CallRole)
+	lv.VisitExpressions(&n.ArgumentList)
 }
 func (lv *DfaVisitor) VisitNullLiteral(n *ast.NullLiteral) {
 
@@ -445,14 +831,15 @@ func (lv *DfaVisitor) VisitParameterList(n *ast.ParameterList) {
 	n.VisitChildrenWith(lv)
 }
 func (lv *DfaVisitor) VisitPrivateDotExpression(n *ast.PrivateDotExpression) {
-
-	n.VisitChildrenWith(lv)
+	lv.visitObject(n)
+	lv.useMember(n)
 }
 func (lv *DfaVisitor) VisitPrivateIdentifier(n *ast.PrivateIdentifier) {
-
-	n.VisitChildrenWith(lv)
+	// Private names, such as the one in #x in obj, belong to a class rather than a scope.
 }
 func (lv *DfaVisitor) VisitProgram(n *ast.Program) {
+	lv.hoistDeclarations(n.Body)
+	lv.declareLexical(n.Body)
 
 	n.VisitChildrenWith(lv)
 }
@@ -477,8 +864,10 @@ func (lv *DfaVisitor) VisitRegExpLiteral(n *ast.RegExpLiteral) {
 	n.VisitChildrenWith(lv)
 }
 func (lv *DfaVisitor) VisitReturnStatement(n *ast.ReturnStatement) {
-
 	n.VisitChildrenWith(lv)
+
+	// Nothing after a return statement falls through.
+	lv.Ctx.exit()
 }
 func (lv *DfaVisitor) VisitSequenceExpression(n *ast.SequenceExpression) {
 
@@ -489,6 +878,8 @@ func (lv *DfaVisitor) VisitSpreadElement(n *ast.SpreadElement) {
 	n.VisitChildrenWith(lv)
 }
 func (lv *DfaVisitor) VisitStatement(n *ast.Statement) {
+	// Any statement may throw, so the definitions reaching it may also reach the enclosing catch or finally block.
+	lv.Ctx.collectThrowState()
 
 	n.VisitChildrenWith(lv)
 }
@@ -505,9 +896,65 @@ func (lv *DfaVisitor) VisitSuperExpression(n *ast.SuperExpression) {
 	n.VisitChildrenWith(lv)
 }
 func (lv *DfaVisitor) VisitSwitchStatement(n *ast.SwitchStatement) {
+	labels := lv.Ctx.takeLabels()
 
-	n.VisitChildrenWith(lv)
+	lv.VisitExpression(n.Discriminant)
+
+	// The switch body is a single lexical scope shared by all of its cases.
+	switchScope := NewScope(false, false)
+	lv.Ctx.pushScope(switchScope)
+	target := lv.Ctx.pushTarget(labels, false, true)
+
+	for i := range n.Body {
+		lv.declareLexical(n.Body[i].Consequent)
+	}
+
+	// Holds the previous case if it falls through into the next one.
+	var fall *Scope
+
+	for i := range n.Body {
+		c := &n.Body[i]
+
+		caseScope := NewScope(true, false)
+		caseScope.CaseScope = true
+		lv.Ctx.pushScope(caseScope)
+
+		if c.Test != nil {
+			lv.VisitExpression(c.Test)
+		}
+
+		if fall != nil {
+			caseScope.MergeSameDepth(fall)
+		}
+
+		lv.VisitCaseStatement(c)
+		lv.Ctx.popScope()
+
+		fall = nil
+		if !caseScope.Unreachable {
+			fall = caseScope
+		}
+	}
+
+	lv.Ctx.popTarget()
+
+	// Holds every path leading out of the switch statement.
+	exits := target.breaks
+	if fall != nil {
+		exits = append(exits, fall)
+	}
+
+	// Without a default case, none of the cases may run.
+	if n.Default < 0 {
+		exits = append(exits, switchScope)
+	}
+
+	lv.Ctx.joinPaths(exits)
+	lv.Ctx.popScope()
+
+	lv.Ctx.mergeDown(lv.Ctx.scopeDepth+1, switchScope, false)
 }
+
 func (lv *DfaVisitor) VisitTemplateElement(n *ast.TemplateElement) {
 
 	n.VisitChildrenWith(lv)
@@ -525,12 +972,118 @@ func (lv *DfaVisitor) VisitThisExpression(n *ast.ThisExpression) {
 	n.VisitChildrenWith(lv)
 }
 func (lv *DfaVisitor) VisitThrowStatement(n *ast.ThrowStatement) {
-
 	n.VisitChildrenWith(lv)
+
+	// The thrown exception carries the definitions after its argument to the closest handler.
+	lv.Ctx.collectThrowState()
+	lv.Ctx.exit()
 }
 func (lv *DfaVisitor) VisitTryStatement(n *ast.TryStatement) {
+	// Collects the definitions reaching every statement in the try block, as any of them may throw.
+	tryHandler := NewScope(false, false)
+
+	// Break and continue statements leaving the try or catch block run the finally block first.
+	var finally *finallyEntry
+	if n.Finally != nil {
+		finally = &finallyEntry{targets: len(lv.Ctx.targets)}
+		lv.Ctx.finallies = append(lv.Ctx.finallies, finally)
+	}
 
-	n.VisitChildrenWith(lv)
+	lv.Ctx.handlers = append(lv.Ctx.handlers, tryHandler)
+	tryScope := NewScope(false, false)
+	lv.Ctx.pushScope(tryScope)
+	lv.VisitBlockStatement(n.Body)
+	lv.Ctx.popScope()
+	lv.Ctx.handlers = lv.Ctx.handlers[:len(lv.Ctx.handlers)-1]
+
+	if n.Catch == nil {
+		lv.Ctx.mergeDown(lv.Ctx.scopeDepth+1, tryScope, false)
+	}
+
+	// Holds the definitions that may be live when an exception escapes to the finally block.
+	thrown := tryHandler
+
+	if n.Catch != nil {
+		if n.Finally != nil {
+			thrown = NewScope(false, false)
+			lv.Ctx.handlers = append(lv.Ctx.handlers, thrown)
+		}
+
+		caught := NewScope(false, false)
+		lv.Ctx.mergeLive(caught, tryHandler)
+
+		catchScope := NewScope(true, false)
+		lv.Ctx.pushScope(catchScope)
+		catchScope.MergeSameDepth(caught)
+		lv.VisitCatchStatement(n.Catch)
+		lv.Ctx.popScope()
+
+		if n.Finally != nil {
+			lv.Ctx.handlers = lv.Ctx.handlers[:len(lv.Ctx.handlers)-1]
+		}
+
+		// Execution continues after either the try block or the catch block completes.
+		lv.Ctx.joinPaths([]*Scope{tryScope, catchScope})
+	}
+
+	if n.Finally == nil {
+		return
+	}
+
+	lv.Ctx.finallies = lv.Ctx.finallies[:len(lv.Ctx.finallies)-1]
+
+	uncaught := NewScope(false, false)
+	lv.Ctx.mergeLive(uncaught, thrown)
+
+	// Holds the definitions at every exit that doesn't fall through the finally block.
+	abrupt := NewScope(false, false)
+	lv.Ctx.mergeLive(abrupt, uncaught)
+	for _, j := range finally.jumps {
+		lv.Ctx.mergeLive(abrupt, j.state)
+	}
+
+	// Whether execution can reach the finally block without an exception.
+	normalExit := !lv.Ctx.scopeStack[lv.Ctx.scopeDepth].Unreachable
+
+	// The finally block runs after both the normal and exceptional exits.
+	finallyScope := NewScope(false, false)
+	lv.Ctx.pushScope(finallyScope)
+	if !normalExit {
+		finallyScope.Definitions = make(ScopeDefs)
+		finallyScope.Unreachable = false
+	}
+	finallyScope.MergeSameDepth(abrupt)
+	entry := make(ScopeDefs)
+	entry.AppendScopeDefs(finallyScope.Definitions)
+
+	lv.VisitBlockStatement(n.Finally)
+	lv.Ctx.popScope()
+
+	// Identifiers the finally block doesn't redefine keep the definitions from the normal exit,
+	// as execution only continues past the finally block if nothing was thrown.
+	for id, defs := range finallyScope.Definitions {
+		if sameDefs(entry[id], defs) {
+			delete(finallyScope.Definitions, id)
+		}
+	}
+
+	if normalExit {
+		lv.Ctx.mergeDown(lv.Ctx.scopeDepth+1, finallyScope, false)
+	}
+
+	// Jumps continue on to their target after the finally block, unless it jumps away itself.
+	if !finallyScope.Unreachable {
+		for _, j := range finally.jumps {
+			overlay(j.state, finallyScope)
+			lv.Ctx.deliver(j.label, j.cont, j.state)
+		}
+	}
+
+	// Uncaught exceptions continue on to the enclosing handler with the definitions the finally block left them.
+	if !finallyScope.Unreachable && len(lv.Ctx.handlers) > 0 {
+		overlay(uncaught, finallyScope)
+		lv.Ctx.mergeLive(lv.Ctx.handlers[len(lv.Ctx.handlers)-1], uncaught)
+	}
 }
 func (lv *DfaVisitor) VisitUnaryExpression(n *ast.UnaryExpression) {
 
@@ -538,36 +1091,54 @@ func (lv *DfaVisitor) VisitUnaryExpression(n *ast.UnaryExpression) {
 }
 
 func (lv *DfaVisitor) VisitUpdateExpression(n *ast.UpdateExpression) {
-
+	// The operand is read before its updated value is written back.
+	switch operand := n.Operand.Expr.(type) {
+	case *ast.Identifier:
+		lv.reference(operand, UpdateRole)
+
+		typ, foundDepth := lv.Ctx.lookupDef(operand.Name)
+		overwrite := !lv.Ctx.conditionalAssignment(operand.Name)
+		lv.Ctx.addValue(lv.Ctx.scopeStack[lv.Ctx.scopeDepth], operand.Name, &ast.Expression{Expr: n}, overwrite, typ, foundDepth)
+	case *ast.MemberExpression, *ast.PrivateDotExpression:
+		lv.visitObject(operand)
+		lv.useMember(operand)
+
+		if base, path, ok := accessPath(operand, lv.Ctx.thisName); ok {
+			lv.defineProperty(base.Name, path, &ast.Expression{Expr: n}, true)
+		}
+	default:
+		n.VisitChildrenWith(lv)
+	}
 }
 
 func (lv *DfaVisitor) VisitVariableDeclaration(n *ast.VariableDeclaration) {
-	// TODO: Declarations with multiple declarations
+	// Declarators run in order, so later declarators see the ones before them.
+	for i := range n.List {
+		lv.declare(n.Token.String(), &n.List[i])
+	}
+}
 
-	val := n.List[0].Initializer
-	if val == nil {
+// declare visits the initializer of a declarator, and then defines its binding.
+// kind is the keyword the declaration uses.
+// d is the declarator.
+func (lv *DfaVisitor) declare(kind string, d *ast.VariableDeclarator) {
+	currentScope := lv.Ctx.scopeStack[lv.Ctx.scopeDepth]
 
+	// The initializer runs before the binding is initialized, so it never sees its own definition.
+	if d.Initializer != nil {
+		lv.VisitExpression(d.Initializer)
 	}
-	switch n.Token.String() {
-	case "var":
-		if i, ok := n.List[0].Target.Target.(*ast.Identifier); ok {
-			lv.Ctx.scopeStack[lv.Ctx.scopeDepth].AddValue(i.Name, val, true, FunctionScope, lv.Ctx.functionScopeDepth)
-		}
-	case "let":
-		if i, ok := n.List[0].Target.Target.(*ast.Identifier); ok {
-			lv.Ctx.scopeStack[lv.Ctx.scopeDepth].AddValue(i.Name, n.List[0].Initializer, true, BlockScope, lv.Ctx.scopeDepth)
-		}
-	case "const":
-		if i, ok := n.List[0].Target.Target.(*ast.Identifier); ok {
-			lv.Ctx.scopeStack[lv.Ctx.scopeDepth].AddValue(i.Name, n.List[0].Initializer, true, BlockScope, lv.Ctx.scopeDepth)
+
+	// Redeclaring a var without an initializer doesn't change its value, but still counts as a declaration.
+	if i, ok := d.Target.Target.(*ast.Identifier); ok && kind == "var" && d.Initializer == nil {
+		if _, declared := currentScope.Definitions[i.Name]; declared {
+			lv.Ctx.defCount++
+			return
 		}
-	default:
-		fmt.Println("Didn't find a keyword")
 	}
 
-	if val != nil {
-		lv.VisitExpression(val)
-	}
+	// let, const and using declarations are all block scoped.
+	lv.bindPattern(d.Target.Target, d.Initializer, true, lv.declarationDefiner(kind))
 }
 
 func (lv *DfaVisitor) VisitVariableDeclarator(n *ast.VariableDeclarator) {
@@ -578,8 +1149,14 @@ func (lv *DfaVisitor) VisitVariableDeclarators(n *ast.VariableDeclarators) {
 	n.VisitChildrenWith(lv)
 }
 func (lv *DfaVisitor) VisitWhileStatement(n *ast.WhileStatement) {
+	_, backEdge, target := lv.visitLoop(n, lv.Ctx.takeLabels(), true, func(*jumpTarget) {
+		lv.VisitExpression(n.Test)
+		lv.VisitStatement(n.Body)
+	})
 
-	n.VisitChildrenWith(lv)
+	// The loop exits when its test fails, either before the first iteration or after any other, or by breaking out of it.
+	currentScope := lv.Ctx.scopeStack[lv.Ctx.scopeDepth]
+	lv.Ctx.joinPaths(append([]*Scope{currentScope, backEdge}, target.breaks...))
 }
 func (lv *DfaVisitor) VisitWithStatement(n *ast.WithStatement) {
 
@@ -0,0 +1,815 @@
+package dfa
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/t14raptor/go-fast/ast"
+	"github.com/t14raptor/go-fast/token"
+)
+
+// Engine is a way of computing the definitions that reach every usage of a program.
+type Engine int
+
+const (
+	// WalkEngine computes reaching definitions in a single walk over the syntax tree, merging the paths out of every statement as it goes.
+	WalkEngine Engine = iota
+	// WorklistEngine computes reaching definitions over the control flow graph of the program,
+	// solving every graph to a fixpoint with a worklist before the usages are resolved.
+	WorklistEngine
+)
+
+// worklist holds the state of the worklist engine.
+// Definitions are keyed by the name of their binding qualified with the scope declaring it, such as "x@2" or "obj@0.a",
+// so bindings that shadow each other are kept apart. Globals that aren't declared anywhere keep their plain name.
+type worklist struct {
+	r   *rdaContext
+	cfg *CFG
+
+	// count is the count of the next definition while the graphs are numbered.
+	count int64
+	// counts holds the count of the first definition every write of an item makes, keyed by the node the item comes from.
+	counts map[ast.VisitableNode][]int64
+	// copies holds the items built from every node, as the items of a finally block are built once for every path leaving it.
+	copies map[ast.VisitableNode][]*Item
+	// created holds the definition count every function is created at.
+	created map[*FunctionGraph]int64
+	// origins holds the loops around the point every function is created at, outermost first.
+	origins map[*FunctionGraph][]*cfgLoop
+	// starts holds the definition count every loop starts at.
+	starts map[*cfgLoop]int64
+	// writes holds every write of a variable itself, in the order they're counted.
+	writes map[Variable][]countedWrite
+
+	// items holds the definitions found for every item that's been solved.
+	items map[*Item]*solvedItem
+	// entries holds the definitions when every function is created, keyed by their qualified names.
+	entries map[*FunctionGraph]ScopeDefs
+	// methods holds the class of every instance method, whose properties of this are initialized by the constructor.
+	methods map[*FunctionGraph]*ast.ClassLiteral
+	// instances holds the definitions when the constructor of every class returns.
+	instances map[*ast.ClassLiteral]ScopeDefs
+
+	// resolved holds the nodes of the items and the graphs whose usages have been resolved.
+	resolved map[any]bool
+}
+
+// countedWrite is a write of a variable, made by an item of a graph.
+type countedWrite struct {
+	count int64
+	graph *FunctionGraph
+}
+
+// solvedItem holds the definitions found for an item.
+type solvedItem struct {
+	// in and out hold the definitions reaching the item, and the ones after it.
+	in, out ScopeDefs
+	// reads holds the definitions reaching every read of the item, keyed by the position of the access.
+	reads map[int][]*ScopeDef
+	// properties holds the definitions of the properties under every read of the whole value of the item, keyed by the position of the access.
+	properties map[int][]*ScopeDef
+	// sites holds the definitions when the item creates every function.
+	sites map[*FunctionGraph]ScopeDefs
+}
+
+// itemKey returns the node an item comes from, which the copies of a finally block share.
+func itemKey(item *Item) ast.VisitableNode {
+	if item.Node == nil || item.Kind == token.Class {
+		return item.Expr.Expr
+	}
+
+	return item.Node
+}
+
+// runWorklist computes the reaching definitions of a program with the worklist engine.
+// The definitions of every item are numbered in the order the code is written in, every graph is solved with the gen and kill
+// sets of its items, and the usages are resolved in the order they're evaluated in.
+func (r *rdaContext) runWorklist(a *ast.Program) {
+	c := BuildCFG(a)
+	w := &worklist{
+		r:         r,
+		cfg:       c,
+		counts:    make(map[ast.VisitableNode][]int64),
+		copies:    make(map[ast.VisitableNode][]*Item),
+		created:   make(map[*FunctionGraph]int64),
+		origins:   make(map[*FunctionGraph][]*cfgLoop),
+		starts:    make(map[*cfgLoop]int64),
+		writes:    make(map[Variable][]countedWrite),
+		items:     make(map[*Item]*solvedItem),
+		entries:   make(map[*FunctionGraph]ScopeDefs),
+		methods:   make(map[*FunctionGraph]*ast.ClassLiteral),
+		instances: make(map[*ast.ClassLiteral]ScopeDefs),
+		resolved:  make(map[any]bool),
+	}
+
+	for _, g := range c.Functions {
+		for _, n := range g.Nodes {
+			for _, item := range n.Items {
+				key := itemKey(item)
+				w.copies[key] = append(w.copies[key], item)
+			}
+		}
+
+		if cl, ok := g.Node.(*ast.ClassLiteral); ok {
+			for i := range cl.Body {
+				if m, ok := cl.Body[i].Element.(*ast.MethodDefinition); ok && !m.Static && !isConstructor(m) {
+					w.methods[c.graphs[m.Body]] = cl
+				}
+			}
+		}
+	}
+
+	w.numberGraph(c.Program, nil)
+
+	// Functions are created by the graph of the function around them, which comes first.
+	for _, g := range c.Functions {
+		w.solve(g)
+	}
+
+	w.resolveGraph(c.Program)
+}
+
+// visitItem calls access for every access an item makes itself, and site for the functions and lowered operands in between them,
+// in the order they're evaluated. i is the position of the access.
+func (w *worklist) visitItem(item *Item, access func(i int, a Access), site func(s accessSite)) {
+	accesses := w.cfg.Accesses(item)
+	sites := w.cfg.sites[item]
+	for i := 0; ; i++ {
+		for len(sites) > 0 && sites[0].pos == i {
+			site(sites[0])
+			sites = sites[1:]
+		}
+
+		if i == len(accesses) || accesses[i].Deferred {
+			return
+		}
+
+		access(i, accesses[i])
+	}
+}
+
+// numberGraph numbers the definitions of a function, which are counted from where it's created.
+// loops holds the loops around the point the function is created at.
+func (w *worklist) numberGraph(g *FunctionGraph, loops []*cfgLoop) {
+	if _, ok := w.created[g]; ok {
+		return
+	}
+
+	// Hoisted functions are created along with the first declaration hoisted with them.
+	w.created[g] = w.count
+	if g.hoisted != nil {
+		w.created[g] = w.counts[itemKey(g.hoisted[0])][0]
+	}
+
+	w.origins[g] = loops
+	for _, item := range g.written() {
+		w.numberItem(item)
+	}
+}
+
+// numberItem numbers the definitions an item makes, along with the ones of the functions and operands it evaluates.
+// The copies of an item in a finally block share the same definitions.
+func (w *worklist) numberItem(item *Item) {
+	key := itemKey(item)
+	if _, ok := w.counts[key]; ok {
+		return
+	}
+
+	for _, l := range item.loops {
+		if _, ok := w.starts[l]; !ok {
+			w.starts[l] = w.count
+		}
+	}
+
+	counts := []int64{}
+	w.counts[key] = counts
+	w.visitItem(item, func(i int, a Access) {
+		if !a.Write {
+			return
+		}
+
+		counts = append(counts, w.count)
+		if a.Path == nil {
+			w.writes[a.Var] = append(w.writes[a.Var], countedWrite{count: w.count, graph: item.scope.graph})
+		}
+
+		w.count += defSize(a.Value)
+	}, func(site accessSite) {
+		if site.graph != nil {
+			w.numberGraph(site.graph, item.loops)
+			return
+		}
+
+		for _, op := range item.operands[site.expr] {
+			w.numberItem(op)
+		}
+	})
+
+	w.counts[key] = counts
+}
+
+// defSize returns the number of definitions a write makes, which is one for the value and one for every property an object literal initializes.
+// v is the value written, or nil if it's unknown.
+func defSize(v *ast.Expression) int64 {
+	size := int64(1)
+	if v == nil {
+		return size
+	}
+
+	lit, ok := v.Expr.(*ast.ObjectLiteral)
+	if !ok {
+		return size
+	}
+
+	for i := range lit.Value {
+		switch p := lit.Value[i].Prop.(type) {
+		case *ast.PropertyShort:
+			size++
+		case *ast.PropertyKeyed:
+			if p.Kind != ast.PropertyKindGet && p.Kind != ast.PropertyKindSet {
+				size += defSize(p.Value)
+			}
+		}
+	}
+
+	return size
+}
+
+// solve computes the definitions reaching every item of a graph.
+// Code that can't be reached, such as the code after a return statement, is solved afterwards with the definitions after the item written before it.
+func (w *worklist) solve(g *FunctionGraph) {
+	facts := SolveForward[ScopeDefs](g, &reachingDefs{w: w, entry: w.entry(g)})
+	if c, ok := g.Node.(*ast.ClassLiteral); ok {
+		w.instances[c] = facts.In[g.Exit]
+	}
+
+	var items []*Item
+	for _, n := range g.Nodes {
+		items = append(items, n.Items...)
+	}
+
+	slices.SortFunc(items, func(a *Item, b *Item) int {
+		return a.index - b.index
+	})
+
+	for i := 1; i < len(items); i++ {
+		prev, ok := w.items[items[i-1]]
+		if _, solved := w.items[items[i]]; solved || !ok {
+			continue
+		}
+
+		w.apply(items[i], w.r.transition(copyDefs(prev.out), items[i-1].scope, items[i].scope))
+	}
+}
+
+// entry returns the definitions a function starts with.
+func (w *worklist) entry(g *FunctionGraph) ScopeDefs {
+	created := make(ScopeDefs)
+	if g == w.cfg.Program {
+		// Globals the program declares itself are left out, as its declarations replace them.
+		for id, defs := range w.r.scopeStack[0].Definitions {
+			if g.Entry.scope.resolve(id) == nil {
+				created[id] = append([]*ScopeDef{}, defs...)
+			}
+		}
+	} else {
+		created = w.createdWith(g)
+	}
+
+	w.entries[g] = created
+
+	// The function starts out with its hoisted variables undefined, and its lexical bindings uninitialized.
+	entry := w.r.transition(copyDefs(created), g.Entry.scope.parent, g.Entry.scope)
+	if _, arrow := g.Node.(*ast.ArrowFunctionLiteral); !arrow && g != w.cfg.Program {
+		entry[qualify("arguments", g.Entry.scope)] = []*ScopeDef{w.r.argumentsDef(g.Node, 0)}
+	}
+
+	for _, id := range g.Entry.scope.vars {
+		entry[qualify(id, g.Entry.scope)] = []*ScopeDef{Undefined}
+	}
+
+	// Every construction starts with a new object, which instance methods see once the constructor returned.
+	if _, ok := g.Node.(*ast.ClassLiteral); ok {
+		copyProperties(&Scope{Definitions: entry}, NewScope(false, false), "this")
+	} else if c, ok := w.methods[g]; ok && w.instances[c] != nil {
+		copyProperties(&Scope{Definitions: entry}, &Scope{Definitions: w.instances[c]}, "this")
+	}
+
+	return entry
+}
+
+// createdWith returns the definitions when a function is created, joining every place that creates it.
+// Hoisted functions are created with the definitions from when they were hoisted.
+func (w *worklist) createdWith(g *FunctionGraph) ScopeDefs {
+	if g.hoisted != nil {
+		if defs := w.hoistedWith(g); defs != nil {
+			return defs
+		}
+	}
+
+	var created ScopeDefs
+	for _, n := range g.Parent.Nodes {
+		for _, item := range n.Items {
+			if s, ok := w.items[item]; ok && s.sites[g] != nil {
+				created = mergeDefs(created, s.sites[g])
+			}
+		}
+	}
+
+	if created == nil {
+		return make(ScopeDefs)
+	}
+
+	return created
+}
+
+// hoistedWith returns the definitions after the declarations a function is hoisted along with, or nil if they haven't been solved.
+func (w *worklist) hoistedWith(g *FunctionGraph) ScopeDefs {
+	s, ok := w.items[g.hoisted[len(g.hoisted)-1]]
+	if !ok {
+		return nil
+	}
+
+	return copyDefs(s.out)
+}
+
+// reachingDefs is the forward analysis the worklist engine solves every graph with.
+// Facts are the definitions reaching a point keyed by their qualified names, and nil is the bottom of the lattice.
+type reachingDefs struct {
+	w *worklist
+	// entry holds the definitions the function starts with.
+	entry ScopeDefs
+}
+
+func (d *reachingDefs) Bottom() ScopeDefs {
+	return nil
+}
+
+// Join merges the definitions of two paths. Bindings that only one of them defines are undefined on the other one.
+func (d *reachingDefs) Join(a ScopeDefs, b ScopeDefs) ScopeDefs {
+	if a == nil {
+		return copyDefs(b)
+	}
+
+	if b == nil {
+		return copyDefs(a)
+	}
+
+	joined := mergeDefs(copyDefs(a), b)
+	for id := range joined {
+		if _, ok := a[id]; !ok {
+			joined[id] = append([]*ScopeDef{Undefined}, joined[id]...)
+		} else if _, ok := b[id]; !ok {
+			joined[id] = appendNew(joined[id], []*ScopeDef{Undefined})
+		}
+	}
+
+	return joined
+}
+
+func (d *reachingDefs) Equal(a ScopeDefs, b ScopeDefs) bool {
+	return equalDefs(a, b)
+}
+
+func (d *reachingDefs) Entry(g *FunctionGraph) ScopeDefs {
+	return d.entry
+}
+
+// Transfer applies the items of a node in turn, recording the definitions reaching each of them.
+func (d *reachingDefs) Transfer(n *GraphNode, in ScopeDefs) ScopeDefs {
+	w := d.w
+	state := copyDefs(in)
+	scope := n.scope
+	for _, item := range n.Items {
+		state = w.r.transition(state, scope, item.scope)
+		scope = item.scope
+		state = w.apply(item, state)
+	}
+
+	return state
+}
+
+// TransferEdge moves definitions from the scope an edge leaves in into the scope of the node it enters.
+func (d *reachingDefs) TransferEdge(e *Edge, f ScopeDefs) ScopeDefs {
+	end := endScope(e.From)
+	if e.Kind == ExceptionEdge {
+		end = e.From.scope
+	}
+
+	return d.w.r.transition(copyDefs(f), end, e.To.scope)
+}
+
+// endScope returns the lexical scope a node ends in.
+func endScope(n *GraphNode) *lexicalScope {
+	if len(n.Items) > 0 {
+		return n.Items[len(n.Items)-1].scope
+	}
+
+	return n.scope
+}
+
+// apply applies the gen and kill sets of an item to the definitions reaching it, and returns the definitions after it.
+// The definitions reaching every read of the item and every function it creates are recorded along the way.
+func (w *worklist) apply(item *Item, state ScopeDefs) ScopeDefs {
+	s := &solvedItem{
+		in:         copyDefs(state),
+		reads:      make(map[int][]*ScopeDef),
+		properties: make(map[int][]*ScopeDef),
+		sites:      make(map[*FunctionGraph]ScopeDefs),
+	}
+
+	view := NewScope(false, false)
+	view.Definitions = project(state, item.scope)
+	counts := w.counts[itemKey(item)]
+
+	w.visitItem(item, func(i int, a Access) {
+		if !a.Write {
+			s.reads[i] = w.read(view, a)
+			if readsWhole(a.Role) {
+				s.properties[i] = propertyDefs(view, pathKey(a.Id.Name, a.Path))
+			}
+			return
+		}
+
+		count := counts[0]
+		counts = counts[1:]
+		w.write(&count, view, item, a)
+	}, func(site accessSite) {
+		if site.graph != nil {
+			s.sites[site.graph] = qualified(state, view.Definitions, item.scope)
+		}
+	})
+
+	s.out = qualified(state, view.Definitions, item.scope)
+	w.items[item] = s
+	return copyDefs(s.out)
+}
+
+// qualified returns a set of definitions with the bindings visible from a scope replaced by the ones in view.
+func qualified(state ScopeDefs, view ScopeDefs, scope *lexicalScope) ScopeDefs {
+	defs := make(ScopeDefs, len(state))
+	for id, list := range state {
+		if !visible(id, scope) {
+			defs[id] = append([]*ScopeDef{}, list...)
+		}
+	}
+
+	for id, list := range view {
+		defs[qualify(id, scope)] = append([]*ScopeDef{}, list...)
+	}
+
+	return defs
+}
+
+// read returns the definitions reaching a read.
+func (w *worklist) read(view *Scope, a Access) []*ScopeDef {
+	if a.Path != nil {
+		return pathDefs(view, a.Id.Name, a.Path)
+	}
+
+	return append([]*ScopeDef{}, view.Definitions[a.Id.Name]...)
+}
+
+// write defines the variable or property a write assigns, killing the definitions it replaces.
+// Writes that only may happen, such as the ones of default values, add to the previous definitions instead.
+// count is the count the definitions of the write are numbered from.
+func (w *worklist) write(count *int64, view *Scope, item *Item, a Access) {
+	name := a.Id.Name
+	typ, depth := binding(item, name)
+	current := view.Definitions
+
+	if a.Path != nil {
+		key := pathKey(name, a.Path)
+		w.r.assignProperty(count, view, key, a.Value, !a.Weak, item.cond && len(current[key]) == 0, typ, depth)
+		return
+	}
+
+	// Redeclaring a var without an initializer doesn't change its value.
+	if _, ok := item.Node.(*ast.VariableDeclarator); ok && item.Kind == token.Var && !item.param && item.Expr == nil {
+		if _, declared := current[name]; declared {
+			return
+		}
+	}
+
+	// Assignments that only run conditionally can't replace the definitions of a variable they don't reach.
+	overwrite := !a.Weak && !(a.reassign && item.cond && len(current[name]) == 0)
+	w.r.addValueAt(count, view, name, a.Value, overwrite, typ, depth)
+}
+
+// binding returns the type and depth of the binding a name resolves to from an item.
+func binding(item *Item, name string) (ScopeDefType, int) {
+	s := item.scope.resolve(name)
+	if s == nil {
+		return GlobalScope, 0
+	}
+
+	depth := 0
+	for p := s.parent; p != nil; p = p.parent {
+		depth++
+	}
+
+	for _, id := range s.lexicals {
+		if id.Name == name {
+			return BlockScope, depth
+		}
+	}
+
+	return FunctionScope, depth
+}
+
+// resolveGraph resolves the usages of a function, in the order they're evaluated in.
+func (w *worklist) resolveGraph(g *FunctionGraph) {
+	if w.resolved[g] {
+		return
+	}
+
+	w.resolved[g] = true
+
+	// The update of a for loop is written ahead of the body, but resolved once the body it runs after is done.
+	var pending []*cfgLoop
+	updates := make(map[*cfgLoop][]*Item)
+	flush := func(loops []*cfgLoop) {
+		for len(pending) > 0 && !slices.Contains(loops, pending[len(pending)-1]) {
+			l := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+			for _, item := range updates[l] {
+				w.resolveItem(item)
+			}
+		}
+	}
+
+	for _, item := range g.written() {
+		flush(item.loops)
+		if !item.update {
+			w.resolveItem(item)
+			continue
+		}
+
+		l := item.loops[len(item.loops)-1]
+		if len(pending) == 0 || pending[len(pending)-1] != l {
+			pending = append(pending, l)
+		}
+
+		updates[l] = append(updates[l], item)
+	}
+
+	flush(nil)
+}
+
+// resolveItem resolves the usages of an item with the definitions reaching them.
+// The usages of an item in a finally block are reached by the definitions reaching any of its copies.
+func (w *worklist) resolveItem(item *Item) {
+	key := itemKey(item)
+	if w.resolved[key] {
+		return
+	}
+
+	w.resolved[key] = true
+
+	var copies []*solvedItem
+	for _, c := range w.copies[key] {
+		if s, ok := w.items[c]; ok {
+			copies = append(copies, s)
+		}
+	}
+
+	w.visitItem(item, func(i int, a Access) {
+		if a.Write || w.r.Ignored[a.Id.Name] {
+			return
+		}
+
+		var defs, properties []*ScopeDef
+		for _, c := range copies {
+			defs = appendNew(defs, c.reads[i])
+			properties = appendNew(properties, c.properties[i])
+		}
+
+		sort.Slice(properties, func(i, j int) bool {
+			return properties[i].Count < properties[j].Count
+		})
+
+		ud := &UseDef{
+			Usage:       a.Id,
+			Path:        a.Path,
+			Definitions: defs,
+			Properties:  properties,
+			Role:        a.Role,
+		}
+
+		w.r.UseDefs = append(w.r.UseDefs, ud)
+
+		// Properties are used along with their object, which is captured instead.
+		if a.Path == nil {
+			w.capture(ud, item, a.Var)
+			w.r.checkTDZ(ud)
+		}
+	}, func(site accessSite) {
+		if site.graph != nil {
+			w.resolveGraph(site.graph)
+			return
+		}
+
+		for _, op := range item.operands[site.expr] {
+			w.resolveItem(op)
+		}
+	})
+}
+
+// capture marks a use as a closure capture if the variable it uses belongs to a graph outside of the one using it.
+// The use is also reached by every definition of the variable that may run after the closure was created.
+// item is the item making the use, and v is the variable it uses.
+func (w *worklist) capture(ud *UseDef, item *Item, v Variable) {
+	g := item.scope.graph
+	owner := w.cfg.Program
+	if v.Scope >= 0 {
+		owner = item.scope.resolve(v.Name).graph
+	}
+
+	if g == owner {
+		return
+	}
+
+	// The closure may run as soon as the outermost function inside of the variable's graph is created.
+	f := g
+	for f.Parent != owner {
+		f = f.Parent
+	}
+
+	var hoisted ScopeDefs
+	if f.hoisted != nil {
+		hoisted = w.hoistedWith(f)
+	}
+
+	loop := int64(-1)
+	for _, l := range w.origins[f] {
+		if v.Scope < 0 || l.scope.declaredIn(v.Scope) {
+			loop = w.starts[l]
+			break
+		}
+	}
+
+	after, ok := markCaptured(ud, v.Scope >= 0 || len(w.entries[f][v.Name]) > 0, w.created[f], hoisted, v.String(), loop)
+	if !ok {
+		return
+	}
+
+	for _, wr := range w.writes[v] {
+		if def, ok := w.r.defRegistry[wr.count]; ok && reachesCapture(wr.count, after, wr.graph != owner) {
+			ud.Definitions = appendNew(ud.Definitions, []*ScopeDef{def})
+		}
+	}
+}
+
+// declaredIn determines if a scope is the scope with the given id, or inside of it.
+func (s *lexicalScope) declaredIn(id int) bool {
+	for ; s != nil; s = s.parent {
+		if s.id == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// transition moves a set of definitions from one scope into another.
+// The bindings of every scope that's left disappear, and the lexical bindings of every scope that's entered are uninitialized.
+func (r *rdaContext) transition(state ScopeDefs, from *lexicalScope, to *lexicalScope) ScopeDefs {
+	if from == to {
+		return state
+	}
+
+	inFrom := make(map[int]bool)
+	for s := from; s != nil; s = s.parent {
+		inFrom[s.id] = true
+	}
+
+	inTo := make(map[int]bool)
+	var entered []*lexicalScope
+	for s := to; s != nil; s = s.parent {
+		inTo[s.id] = true
+		if !inFrom[s.id] {
+			entered = append(entered, s)
+		}
+	}
+
+	for id := range state {
+		if scope, ok := qualifier(id); ok && inFrom[scope] != inTo[scope] {
+			delete(state, id)
+		}
+	}
+
+	for _, s := range entered {
+		for _, id := range s.lexicals {
+			state[qualify(id.Name, s)] = []*ScopeDef{r.tdzDef(id, 0)}
+		}
+	}
+
+	return state
+}
+
+// qualify returns the key of an access path qualified with the scope its binding resolves to from a scope.
+func qualify(key string, scope *lexicalScope) string {
+	base, _ := splitPath(key)
+	s := scope.resolve(base)
+	if s == nil {
+		return key
+	}
+
+	return base + "@" + strconv.Itoa(s.id) + key[len(base):]
+}
+
+// qualifier returns the id of the scope a qualified key belongs to, or false if it's a global.
+func qualifier(key string) (int, bool) {
+	base, _ := splitPath(key)
+	at := strings.LastIndexByte(base, '@')
+	if at < 0 {
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(base[at+1:])
+	return id, err == nil
+}
+
+// visible determines if a qualified key refers to the binding a name resolves to from a scope.
+func visible(key string, scope *lexicalScope) bool {
+	base, _ := splitPath(key)
+	at := strings.LastIndexByte(base, '@')
+	if at < 0 {
+		return scope.resolve(base) == nil
+	}
+
+	s := scope.resolve(base[:at])
+	return s != nil && strconv.Itoa(s.id) == base[at+1:]
+}
+
+// project returns the definitions visible from a scope, keyed by the plain name of their binding.
+func project(state ScopeDefs, scope *lexicalScope) ScopeDefs {
+	view := make(ScopeDefs)
+	for id, defs := range state {
+		if !visible(id, scope) {
+			continue
+		}
+
+		base, _ := splitPath(id)
+		name := base
+		if at := strings.LastIndexByte(base, '@'); at >= 0 {
+			name = base[:at]
+		}
+
+		view[name+id[len(base):]] = append([]*ScopeDef{}, defs...)
+	}
+
+	return view
+}
+
+// equalDefs determines if two sets of definitions are the same.
+func equalDefs(a ScopeDefs, b ScopeDefs) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for id, defs := range a {
+		other, ok := b[id]
+		if !ok || !sameDefs(defs, other) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// copyDefs returns a copy of a set of definitions that doesn't share any lists with it.
+func copyDefs(defs ScopeDefs) ScopeDefs {
+	c := make(ScopeDefs, len(defs))
+	for id, list := range defs {
+		c[id] = append([]*ScopeDef{}, list...)
+	}
+
+	return c
+}
+
+// appendNew appends every definition that isn't already in the list.
+func appendNew(list []*ScopeDef, defs []*ScopeDef) []*ScopeDef {
+	for _, def := range defs {
+		if def != nil && !containsDef(list, def) {
+			list = append(list, def)
+		}
+	}
+
+	return list
+}
+
+// mergeDefs appends the definitions of every binding in defs to the ones of the same binding in dst, which may be nil.
+func mergeDefs(dst ScopeDefs, defs ScopeDefs) ScopeDefs {
+	if dst == nil {
+		dst = make(ScopeDefs, len(defs))
+	}
+
+	for id, list := range defs {
+		dst[id] = appendNew(dst[id], list)
+	}
+
+	return dst
+}
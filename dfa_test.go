@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"io"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/civiledcode/javascribe/dfa"
@@ -11,14 +13,34 @@ import (
 )
 
 var testsRan = []string{
-	"010", "011", "012", "013", "014", "015", "016", // 01.
+	"010", "011", "012", "013", "014", "015", "016", "017", "018", "019", // 01.
 	"020", "021", "022", "023", "024", "025", "026", "027", "028", "029", // 02.
-	"030", "031", "032", "033", "034", "035", // 03.
+	"030", "031", "032", "033", "034", "035", "036", // 03.
+	"040", "041", "042", "043", "044", // 04.
+	"050", "051", "052", "053", "054", // 05.
+	"060", "061", "062", "063", "064", "065", "066", "067", "068", // 06.
+	"070", "071", "072", "073", "074", // 07.
+	"080", "081", "082", "083", "084", "085", "086", "087", "088", // 08.
+	"090", "091", "092", // 09.
+	"100", "101", "102", "103", "104", "105", // 10.
+	"110", "111", "112", "113", "114", "115", "116", // 11.
+	"120", "121", "122", "123", "124", "125", "126", "127", "128", "129", // 12.
+	"130", "131", "132", "133", "134", "135", "136", "137", "138", // 13.
+	"140", "141", "142", // 14.
+	"150", "151", // 15.
+	"160", "161", "162", "163", // 16.
 }
 
 type testResult struct {
 	Identifer string  `json:"id"`
+	Path      string  `json:"path,omitempty"`
 	Assigns   []int64 `json:"assigns"`
+	Captured  bool    `json:"captured,omitempty"`
+	TDZ       bool    `json:"tdz,omitempty"`
+	Role      string  `json:"role,omitempty"`
+	// Env depicts if a global provided by the environment reaches the usage.
+	Env        bool `json:"env,omitempty"`
+	Unresolved bool `json:"unresolved,omitempty"`
 }
 
 type testResults struct {
@@ -26,6 +48,16 @@ type testResults struct {
 }
 
 func TestDFA(t *testing.T) {
+	runDFA(t, dfa.WalkEngine)
+}
+
+// TestDFAWorklist runs the fixtures against the worklist engine.
+func TestDFAWorklist(t *testing.T) {
+	runDFA(t, dfa.WorklistEngine)
+}
+
+// runDFA checks the usages found by an engine against every fixture.
+func runDFA(t *testing.T, engine dfa.Engine) {
 	for _, testName := range testsRan {
 		testFile := testName + ".js"
 		testOutput := testName + ".json"
@@ -67,6 +99,9 @@ func TestDFA(t *testing.T) {
 		}
 
 		rdaCtx := dfa.CreateContextRDA(256)
+		rdaCtx.AddGlobals(dfa.ECMAScriptGlobals...)
+		rdaCtx.Ignore("log")
+		rdaCtx.Engine = engine
 		//rdaCtx.Debug = true
 
 		rdaCtx.Start(a)
@@ -90,23 +125,45 @@ func TestDFA(t *testing.T) {
 			}
 
 			expected := res.Expected[idx]
+			path := strings.Join(ud.Path, ".")
+
+			if expected.Identifer != ud.Usage.Name || expected.Path != path {
+				logFail(expected, testResult{Identifer: ud.Usage.Name, Path: path, Assigns: nums, Captured: ud.Captured, TDZ: ud.TDZ}, t, testName)
+
+			}
+
+			if expected.Captured != ud.Captured || expected.TDZ != ud.TDZ {
+				logFail(expected, testResult{Identifer: ud.Usage.Name, Path: path, Assigns: nums, Captured: ud.Captured, TDZ: ud.TDZ}, t, testName)
+			}
 
-			if expected.Identifer != ud.Usage.Name {
-				logFail(expected, testResult{Identifer: ud.Usage.Name, Assigns: nums}, t, testName)
+			env := false
+			for _, def := range ud.Definitions {
+				if def != nil && def.Environment {
+					env = true
+				}
+			}
+
+			if expected.Env != env || expected.Unresolved != ud.Unresolved {
+				logFail(expected, testResult{Identifer: ud.Usage.Name, Path: path, Assigns: nums, Captured: ud.Captured, TDZ: ud.TDZ, Env: env, Unresolved: ud.Unresolved}, t, testName)
+			}
 
+			// Roles are only checked by the tests that expect them.
+			if expected.Role != "" && expected.Role != ud.Role.String() {
+				logFail(expected, testResult{Identifer: ud.Usage.Name, Path: path, Assigns: nums, Captured: ud.Captured, TDZ: ud.TDZ, Role: ud.Role.String()}, t, testName)
 			}
 
+			// The engines may find the definitions reaching a usage in a different order.
 			if len(expected.Assigns) != len(nums) {
-				logFail(expected, testResult{Identifer: ud.Usage.Name, Assigns: nums}, t, testName)
+				logFail(expected, testResult{Identifer: ud.Usage.Name, Path: path, Assigns: nums, Captured: ud.Captured, TDZ: ud.TDZ}, t, testName)
 			} else {
-				for x, num := range nums {
-					if num != expected.Assigns[x] {
-						logFail(expected, testResult{Identifer: ud.Usage.Name, Assigns: nums}, t, testName)
+				for _, num := range nums {
+					if !slices.Contains(expected.Assigns, num) {
+						logFail(expected, testResult{Identifer: ud.Usage.Name, Path: path, Assigns: nums, Captured: ud.Captured, TDZ: ud.TDZ}, t, testName)
 					}
 				}
 			}
 
-			t.Logf("PASS: Identifier: %s   Assigns: %v", ud.Usage.Name, nums)
+			t.Logf("PASS: Identifier: %s   Path: %s   Assigns: %v", ud.Usage.Name, path, nums)
 		}
 
 		t.Logf("Test %s PASSED!\n\n", testName)
@@ -114,5 +171,158 @@ func TestDFA(t *testing.T) {
 }
 
 func logFail(expected testResult, got testResult, t *testing.T, testname string) {
-	t.Fatalf("incorrect result from test %s.js:\nexpected: id=%s assigns=%v\ngot:      id=%s assigns=%v", testname, expected.Identifer, expected.Assigns, got.Identifer, got.Assigns)
+	t.Fatalf("incorrect result from test %s.js:\nexpected: id=%s path=%s assigns=%v captured=%v tdz=%v env=%v unresolved=%v role=%s\ngot:      id=%s path=%s assigns=%v captured=%v tdz=%v env=%v unresolved=%v role=%s", testname, expected.Identifer, expected.Path, expected.Assigns, expected.Captured, expected.TDZ, expected.Env, expected.Unresolved, expected.Role, got.Identifer, got.Path, got.Assigns, got.Captured, got.TDZ, got.Env, got.Unresolved, got.Role)
+}
+
+// unusedExpected holds the definitions no usage is reached by, for the fixtures that check them.
+var unusedExpected = map[string][]int64{
+	"013": {0, 2},
+	"028": {1},
+	"163": {5, 6},
+}
+
+// TestDefUse checks that the def-use chains are the inverse of the use-def chains for every fixture.
+func TestDefUse(t *testing.T) {
+	for _, engine := range []dfa.Engine{dfa.WalkEngine, dfa.WorklistEngine} {
+		for _, testName := range testsRan {
+			jsCode, err := os.ReadFile("./js_tests/" + testName + ".js")
+			if err != nil {
+				panic(err)
+			}
+
+			a, err := parser.ParseFile(string(jsCode))
+			if err != nil {
+				panic(err)
+			}
+
+			rdaCtx := dfa.CreateContextRDA(256)
+			rdaCtx.AddGlobals(dfa.ECMAScriptGlobals...)
+			rdaCtx.Ignore("log")
+			rdaCtx.Engine = engine
+			rdaCtx.Start(a)
+
+			used := make(map[*dfa.ScopeDef]bool)
+			for _, ud := range rdaCtx.UseDefs {
+				for _, def := range append(ud.Definitions, ud.Properties...) {
+					if def == nil || def == dfa.Undefined {
+						continue
+					}
+
+					used[def] = true
+					if !slices.Contains(rdaCtx.UsesOf(def), ud) {
+						t.Fatalf("test %s: usage of %s missing from the uses of definition %d", testName, ud.Usage.Name, def.Count)
+					}
+				}
+			}
+
+			for def := range used {
+				for _, ud := range rdaCtx.UsesOf(def) {
+					if !slices.Contains(ud.Definitions, def) && !slices.Contains(ud.Properties, def) {
+						t.Fatalf("test %s: definition %d isn't reaching usage of %s", testName, def.Count, ud.Usage.Name)
+					}
+				}
+			}
+
+			var unused []int64
+			for _, def := range rdaCtx.UnusedDefs() {
+				if used[def] {
+					t.Fatalf("test %s: definition %d is used", testName, def.Count)
+				}
+
+				unused = append(unused, def.Count)
+			}
+
+			if expected, ok := unusedExpected[testName]; ok && !slices.Equal(expected, unused) {
+				t.Fatalf("test %s: expected unused definitions %v, got %v", testName, expected, unused)
+			}
+		}
+	}
+}
+
+// TestDiagnostics checks that a use inside of a temporal dead zone is reported at the identifier using it,
+// and that nothing is reported for code without one.
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		src string
+		// name is the name of the reported usage, and offset is its offset in src, or empty if nothing is reported.
+		name   string
+		offset int
+	}{
+		{src: "log(x);\nlet x = 1;\n", name: "x", offset: 4},
+		{src: "let x = 1;\nlog(x);\n"},
+		{src: "for (let x of x) {}\n", name: "x", offset: 14},
+	}
+
+	for _, engine := range []dfa.Engine{dfa.WalkEngine, dfa.WorklistEngine} {
+		for _, test := range tests {
+			a, err := parser.ParseFile(test.src)
+			if err != nil {
+				panic(err)
+			}
+
+			rdaCtx := dfa.CreateContextRDA(256)
+			rdaCtx.Ignore("log")
+			rdaCtx.Engine = engine
+			rdaCtx.Start(a)
+
+			diagnostics := rdaCtx.Diagnostics()
+			if test.name == "" {
+				if len(diagnostics) != 0 {
+					t.Fatalf("%q: expected no diagnostics, got %d", test.src, len(diagnostics))
+				}
+				continue
+			}
+
+			if len(diagnostics) != 1 {
+				t.Fatalf("%q: expected a single diagnostic, got %d", test.src, len(diagnostics))
+			}
+
+			usage := diagnostics[0].Usage
+			if usage.Name != test.name || int(usage.Idx) != test.offset+1 {
+				t.Fatalf("%q: expected %s at %d, got %s at %d", test.src, test.name, test.offset+1, usage.Name, usage.Idx)
+			}
+		}
+	}
+}
+
+// TestGlobals checks that the globals of every environment preset resolve to the environment,
+// while names outside of the preset stay unresolved.
+func TestGlobals(t *testing.T) {
+	tests := []struct {
+		globals []string
+		// known is a global the preset provides, and unknown is one it doesn't.
+		known, unknown string
+	}{
+		{globals: dfa.ECMAScriptGlobals, known: "Math", unknown: "window"},
+		{globals: dfa.BrowserGlobals, known: "window", unknown: "process"},
+		{globals: dfa.NodeGlobals, known: "process", unknown: "window"},
+		{globals: dfa.NodeGlobals, known: "require", unknown: "document"},
+	}
+
+	for _, engine := range []dfa.Engine{dfa.WalkEngine, dfa.WorklistEngine} {
+		for _, test := range tests {
+			a, err := parser.ParseFile(test.known + ";\n" + test.unknown + ";\n")
+			if err != nil {
+				panic(err)
+			}
+
+			rdaCtx := dfa.CreateContextRDA(256)
+			rdaCtx.AddGlobals(test.globals...)
+			rdaCtx.Engine = engine
+			rdaCtx.Start(a)
+
+			if len(rdaCtx.UseDefs) != 2 {
+				t.Fatalf("expected 2 usages, got %d", len(rdaCtx.UseDefs))
+			}
+
+			known := rdaCtx.UseDefs[0]
+			if known.Unresolved || len(known.Definitions) != 1 || !known.Definitions[0].Environment {
+				t.Fatalf("%s: expected the environment to define it", test.known)
+			}
+
+			if unknown := rdaCtx.UseDefs[1]; !unknown.Unresolved {
+				t.Fatalf("%s: expected it to be unresolved", test.unknown)
+			}
+		}
+	}
 }
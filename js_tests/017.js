@@ -0,0 +1,9 @@
+/*
+    017: Demonstrates a var declaration hoisted to the top of its scope, starting out undefined.
+*/
+
+log(x);
+
+var x = 1;      // 0
+
+log(x);
@@ -0,0 +1,9 @@
+/*
+    018: Demonstrates using a let binding before its declaration, inside of the binding's temporal dead zone.
+*/
+
+log(x);
+
+let x = 0;      // 0
+
+log(x);
@@ -0,0 +1,14 @@
+/*
+    019: Demonstrates a const binding in a block shadowing an outer binding before its declaration, and a closure using a binding declared after it.
+*/
+
+var y = 0;              // 0
+
+if (log) {
+    log(y);
+    const y = 1;        // 1
+}
+
+let f = () => z;        // 2
+
+let z = 2;              // 3
@@ -0,0 +1,20 @@
+/*
+    036: Demonstrates the update of a for loop running after the body, which continue statements jump to.
+*/
+
+var x = 0;              // 0
+
+for (
+    var i = 0;          // 1
+    i < 10;
+    i += x              // 2
+) {
+    if (log) {
+        x = 1;          // 3
+        continue;
+    }
+
+    x = 2;              // 4
+}
+
+log(x);
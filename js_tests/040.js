@@ -0,0 +1,11 @@
+/*
+    040: Demonstrates a basic for of loop with a const binding.
+*/
+
+var arr = [1, 2, 3];    // 0
+
+for (const v of arr) {  // 1
+    log(v);
+}
+
+log(v);
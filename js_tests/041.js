@@ -0,0 +1,11 @@
+/*
+    041: Demonstrates a for in loop with a var binding that outlives the loop.
+*/
+
+var obj = {a: 1};       // 0, 1
+
+for (var k in obj) {    // 2
+    log(k);
+}
+
+log(k);
@@ -0,0 +1,12 @@
+/*
+    042: Demonstrates a for of loop assigning to an existing variable.
+*/
+
+let v = 0;          // 0
+var arr = [1, 2];   // 1
+
+for (v of arr) {    // 2
+    log(v);
+}
+
+log(v);
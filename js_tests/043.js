@@ -0,0 +1,13 @@
+/*
+    043: Demonstrates a definition in a for of loop reaching the next iteration.
+*/
+
+var x = 0;              // 0
+var arr = [1, 2];       // 1
+
+for (let v of arr) {    // 2
+    log(x);
+    x = 10;             // 3
+}
+
+log(x);
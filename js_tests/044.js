@@ -0,0 +1,14 @@
+/*
+    044: Demonstrates nested for in and for of loops with block scoped bindings.
+*/
+
+var obj = {a: [1]};             // 0, 1
+
+for (const k in obj) {          // 2
+    for (let v of obj) {        // 3
+        let y = 20;             // 4
+        log(k, v, y);
+    }
+    log(v);
+    log(y);
+}
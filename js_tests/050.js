@@ -0,0 +1,15 @@
+/*
+    050: Demonstrates a basic while loop with a definition reaching the next iteration.
+*/
+
+var x = 0;      // 0
+var i = 0;      // 1
+
+while (i < 10) {
+    log(x);
+    x = 20;     // 2
+    i++;        // 3
+}
+
+log(x);
+log(i);
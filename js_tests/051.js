@@ -0,0 +1,12 @@
+/*
+    051: Demonstrates a do while loop running its body at least once.
+*/
+
+var x = 0;      // 0
+
+do {
+    log(x);
+    x = 5;      // 1
+} while (x < 10);
+
+log(x);
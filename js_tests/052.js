@@ -0,0 +1,16 @@
+/*
+    052: Demonstrates variables expiring out of scope properly in while loops.
+*/
+
+var x = 20;     // 0
+
+while (x < 50) {
+    x = 50;     // 1
+    z = 30;     // 2
+    let y = 20; // 3
+    log(y);
+}
+
+log(x);
+log(y);
+log(z);
@@ -0,0 +1,17 @@
+/*
+    053: Demonstrates nested while loops carrying definitions through both loops.
+*/
+
+var a = 1;          // 0
+
+while (a < 10) {
+    while (a < 5) {
+        log(a);
+        a = 2;      // 1
+    }
+    log(a);
+    b = 3;          // 2
+}
+
+log(a);
+log(b);
@@ -0,0 +1,14 @@
+/*
+    054: Demonstrates a do while loop with a block scoped variable.
+*/
+
+var x = 10;     // 0
+
+do {
+    let y = x;  // 1
+    log(y);
+    x = 20;     // 2
+} while (x < 15);
+
+log(x);
+log(y);
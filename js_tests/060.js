@@ -0,0 +1,17 @@
+/*
+    060: Demonstrates partial try block definitions reaching the catch block.
+*/
+
+var x = 10;         // 0
+
+try {
+    x = 20;         // 1
+    f();
+    x = 30;         // 2
+} catch (e) {       // 3
+    log(x);
+    log(e);
+}
+
+log(x);
+log(e);
@@ -0,0 +1,14 @@
+/*
+    061: Demonstrates a try finally statement without a catch block.
+*/
+
+var x = 10;         // 0
+
+try {
+    f();
+    x = 20;         // 1
+} finally {
+    log(x);
+}
+
+log(x);
@@ -0,0 +1,21 @@
+/*
+    062: Demonstrates a try catch finally statement.
+*/
+
+var x = 10;         // 0
+var y = 1;          // 1
+
+try {
+    x = 20;         // 2
+    f();
+} catch (e) {       // 3
+    x = 30;         // 4
+    f();
+    y = 2;          // 5
+} finally {
+    log(x);
+    y = 3;          // 6
+}
+
+log(x);
+log(y);
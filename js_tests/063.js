@@ -0,0 +1,13 @@
+/*
+    063: Demonstrates definitions that only exist in a catch block.
+*/
+
+try {
+    f();
+} catch (e) {       // 0
+    var z = e;      // 1
+    let w = 5;      // 2
+}
+
+log(z);
+log(w);
@@ -0,0 +1,20 @@
+/*
+    064: Demonstrates nested try statements.
+*/
+
+var x = 0;              // 0
+
+try {
+    try {
+        x = 1;          // 1
+        f();
+        x = 2;          // 2
+    } finally {
+        g();
+    }
+    x = 3;              // 3
+} catch (e) {           // 4
+    log(x);
+}
+
+log(x);
@@ -0,0 +1,15 @@
+/*
+    065: Demonstrates a break statement running the finally block before leaving the loop.
+*/
+
+let x = 0;              // 0
+
+while (c) {
+    try {
+        break;
+    } finally {
+        x = 1;          // 1
+    }
+}
+
+log(x);
@@ -0,0 +1,16 @@
+/*
+    066: Demonstrates a labelled break statement running the finally block before leaving the labelled statement.
+*/
+
+let x = 0;              // 0
+
+outer: {
+    try {
+        break outer;
+    } finally {
+        x = 1;          // 1
+    }
+    x = 2;              // 2
+}
+
+log(x);
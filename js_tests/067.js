@@ -0,0 +1,15 @@
+/*
+    067: Demonstrates a continue statement running the finally block before the next iteration.
+*/
+
+let x = 0;              // 0
+
+for (const item of items) {     // 1
+    try {
+        continue;
+    } finally {
+        x = 2;          // 2
+    }
+}
+
+log(x);
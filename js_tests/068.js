@@ -0,0 +1,17 @@
+/*
+    068: Demonstrates an exception rethrown after a finally block, carrying the definitions the finally block made.
+    The definitions reaching the finally block also reach the catch block, as its statements may throw themselves.
+*/
+
+var x = 0;              // 0
+
+try {
+    try {
+        x = 1;          // 1
+        f();
+    } finally {
+        x = 2;          // 2
+    }
+} catch (e) {           // 3
+    log(x);
+}
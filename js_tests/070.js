@@ -0,0 +1,16 @@
+/*
+    070: Demonstrates assignments to properties, which use the object and any computed key without redefining the object.
+*/
+
+let obj = {};           // 0
+let arr = [];           // 1
+let i = 0;              // 2
+
+obj.x = i;              // 3
+obj.inner.y = 1;        // 4
+arr[i] = obj;           // 5
+obj["z"] += 2;          // 6
+[obj.a, arr[i]] = log;  // 7, 8
+
+obj;
+arr;
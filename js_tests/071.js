@@ -0,0 +1,26 @@
+/*
+    071: Demonstrates field sensitive definitions of properties, killed when the object they belong to is replaced.
+*/
+
+let obj = {a: 1, b: 2};     // 0, 1, 2
+obj.a = 3;                  // 3
+log(obj.a);
+log(obj.b);
+
+if (log) {
+    obj.b = 4;              // 4
+}
+
+log(obj.b);
+
+obj[log] = 5;               // 5
+log(obj.a);
+
+obj = {};                   // 6
+log(obj.a);
+
+obj.c.d = 7;                // 7
+log(obj.c.d);
+
+obj.c = 8;                  // 8
+log(obj.c.d);
@@ -0,0 +1,17 @@
+/*
+    072: Demonstrates update expressions nested inside of other expressions, which use and then redefine their operand.
+*/
+
+let i = 0;          // 0
+let arr = [];       // 1
+
+arr[i++] = 1;       // 2, 3
+log(i);
+
+let a = ++i;        // 4, 5
+log(i--);           // 6
+log(a, i);
+
+let obj = {n: 0};   // 7, 8
+obj.n++;            // 9
+log(obj.n);
@@ -0,0 +1,11 @@
+/*
+    073: Demonstrates numeric property keys, which name the same property as the string they convert to.
+*/
+
+let obj = {0: 1, 1.5: 2};   // 0, 1, 2
+log(obj[0]);
+log(obj["0"]);
+
+obj["0"] = 3;               // 3
+log(obj[0]);
+log(obj[1.5]);
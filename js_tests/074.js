@@ -0,0 +1,11 @@
+/*
+    074: Demonstrates numeric property keys converting to exponent form, which name the same property as that string.
+*/
+
+let obj = {};               // 0
+obj[1e21] = 1;              // 1
+obj[1e-7] = 2;              // 2
+
+log(obj["1e+21"]);
+log(obj["1e-7"]);
+log(obj["1000000000000000000000"]);
@@ -0,0 +1,8 @@
+/*
+    080: Demonstrates function parameters as definitions.
+*/
+
+function f(a, b) {      // 0 1 2
+    log(a);
+    log(b);
+}
@@ -0,0 +1,12 @@
+/*
+    081: Demonstrates var declarations staying inside of the function they're declared in.
+*/
+
+var x = 0;          // 1
+
+function f() {      // 0
+    var x = 1;      // 2
+    log(x);
+}
+
+log(x);
@@ -0,0 +1,8 @@
+/*
+    082: Demonstrates default and rest parameters.
+*/
+
+function f(a, b = a, ...c) {    // 0 1 2 3
+    log(b);
+    log(c);
+}
@@ -0,0 +1,9 @@
+/*
+    083: Demonstrates a function declaration defining its name in the enclosing scope.
+*/
+
+function f() {}     // 0
+
+var g = f;          // 1
+
+g();
@@ -0,0 +1,13 @@
+/*
+    084: Demonstrates nested functions, where the inner function sees the outer function's parameters.
+*/
+
+var x = 0;              // 1
+
+function f(a) {         // 0 2
+    function g() {      // 3
+        var x = a;      // 4
+        log(x);
+    }
+    log(x);
+}
@@ -0,0 +1,7 @@
+/*
+    085: Demonstrates a function declaration hoisted above a call to it.
+*/
+
+f();
+
+function f() {}     // 0
@@ -0,0 +1,11 @@
+/*
+    086: Demonstrates a var declaration in a function shadowing an outer variable from the start of the function.
+*/
+
+var x = 0;          // 1
+
+function f() {      // 0
+    log(x);
+    var x = 1;      // 2
+    log(x);
+}
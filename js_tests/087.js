@@ -0,0 +1,11 @@
+/*
+    087: Demonstrates a hoisted function called before the definitions it uses.
+*/
+
+function f() {          // 0
+    g();
+    var x = 1;          // 2
+    function g() {      // 1
+        log(x);
+    }
+}
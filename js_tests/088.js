@@ -0,0 +1,13 @@
+/*
+    088: Demonstrates the arguments of a function, which every function except arrow functions binds implicitly.
+*/
+
+function f(a) {             // 0, 1
+    log(arguments);
+
+    let g = () => arguments;    // 2
+    return g;
+}
+
+log(arguments);
+f(1);
@@ -0,0 +1,17 @@
+/*
+    090: Demonstrates a bare block whose let and const bindings shadow the outer bindings until the block ends.
+*/
+
+let x = 1;          // 0
+var y = 2;          // 1
+
+{
+    let x = 3;      // 2
+    const z = 4;    // 3
+    y = x;          // 4
+    log(x, z);
+}
+
+log(x);
+log(y);
+log(z);
@@ -0,0 +1,17 @@
+/*
+    091: Demonstrates nested blocks, where an inner binding shadows from the start of its block and assignments to outer bindings fall through.
+*/
+
+let x = 1;          // 0
+
+{
+    let y = 2;      // 1
+    {
+        x = y;      // 2
+        let y = 3;  // 3
+    }
+    log(y);
+}
+
+log(x);
+log(y);
@@ -0,0 +1,15 @@
+/*
+    092: Demonstrates block scoped loop bindings shadowing outer bindings only inside of the loop.
+*/
+
+let i = 1;                      // 0
+
+for (let i = 0; i < 2; i++) {   // 1, 2
+    log(i);
+}
+
+for (const i of log) {          // 3
+    log(i);
+}
+
+log(i);
@@ -0,0 +1,18 @@
+/*
+    100: Demonstrates a switch statement where every case breaks.
+*/
+
+var x = 0;          // 0
+var s = 1;          // 1
+
+switch (s) {
+    case 0:
+        x = 10;     // 2
+        break;
+    case 1:
+        log(x);
+        x = 20;     // 3
+        break;
+}
+
+log(x);
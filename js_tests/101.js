@@ -0,0 +1,19 @@
+/*
+    101: Demonstrates fall through from one case into the next.
+*/
+
+var x = 0;          // 0
+var s = 1;          // 1
+
+switch (s) {
+    case 0:
+        x = 10;     // 2
+    case 1:
+        log(x);
+        x = 20;     // 3
+        break;
+    case 2:
+        x = 30;     // 4
+}
+
+log(x);
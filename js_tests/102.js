@@ -0,0 +1,16 @@
+/*
+    102: Demonstrates a default case replacing the definitions before the switch.
+*/
+
+var x = 0;          // 0
+var s = 1;          // 1
+
+switch (s) {
+    case 0:
+        x = 10;     // 2
+        break;
+    default:
+        x = 20;     // 3
+}
+
+log(x);
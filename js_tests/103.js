@@ -0,0 +1,17 @@
+/*
+    103: Demonstrates a variable that is only defined in some cases.
+*/
+
+var s = 1;          // 0
+
+switch (s) {
+    case 0:
+        z = 10;     // 1
+        break;
+    default:
+        log(s);
+    case 1:
+        z = 20;     // 2
+}
+
+log(z);
@@ -0,0 +1,15 @@
+/*
+    104: Demonstrates block scoped variables sharing the switch body scope.
+*/
+
+var s = 1;          // 0
+
+switch (s) {
+    case 0:
+        let y = 10; // 1
+    case 1:
+        log(y);
+        break;
+}
+
+log(y);
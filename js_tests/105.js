@@ -0,0 +1,10 @@
+/*
+    105: Demonstrates a let binding in a switch case that may be used in its temporal dead zone by a later case.
+*/
+
+switch (log) {
+    case 0:
+        let x = 0;  // 0
+    case 1:
+        log(x);
+}
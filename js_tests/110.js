@@ -0,0 +1,9 @@
+/*
+    110: Demonstrates a named function expression, whose name is only defined inside of itself.
+*/
+
+var f = function g() {  // 1 0
+    log(g);
+};
+
+log(g);
@@ -0,0 +1,9 @@
+/*
+    111: Demonstrates an arrow function with an expression body and a parameter shadowing a variable.
+*/
+
+var a = 0;              // 0
+
+var f = (a) => a + 1;   // 2 1
+
+log(a);
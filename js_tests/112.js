@@ -0,0 +1,13 @@
+/*
+    112: Demonstrates a return statement inside of an arrow function not ending the code around it.
+*/
+
+var x = 0;          // 0
+
+var f = () => {     // 1
+    return;
+};
+
+x = 1;              // 2
+
+log(x);
@@ -0,0 +1,15 @@
+/*
+    113: Demonstrates a function literal inside of a loop body.
+*/
+
+var x = 0;                  // 0
+
+while (log) {
+    var f = function () {   // 2
+        var y = x;          // 1
+        return y;
+    };
+    x = 1;                  // 3
+}
+
+log(x);
@@ -0,0 +1,9 @@
+/*
+    114: Demonstrates a closure using a definition made after the closure was created.
+*/
+
+var x = 0;              // 0
+
+var f = () => log(x);   // 1
+
+x = 1;                  // 2
@@ -0,0 +1,11 @@
+/*
+    115: Demonstrates a closure created in a loop using definitions from the next iteration.
+*/
+
+var x = 0;              // 0
+
+while (log) {
+    x = 1;              // 1
+    var f = () => x;    // 2
+    x = 2;              // 3
+}
@@ -0,0 +1,19 @@
+/*
+    116: Demonstrates a closure using a definition made inside of another closure.
+*/
+
+var x = 0;                  // 1
+
+var f = () => {             // 3
+    x = 1;                  // 2
+};
+
+var g = () => log(x);       // 4
+
+function h() {              // 0
+    var y = 0;              // 5
+    var k = () => log(y);   // 6
+    y = 1;                  // 7
+}
+
+log(x);
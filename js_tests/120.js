@@ -0,0 +1,13 @@
+/*
+    120: Demonstrates a break leaving a while loop before the rest of the body.
+*/
+
+var x = 0;          // 0
+
+while (log) {
+    x = 1;          // 1
+    break;
+    x = 2;          // 2
+}
+
+log(x);
@@ -0,0 +1,16 @@
+/*
+    121: Demonstrates a continue carrying its definitions back to the start of a loop.
+*/
+
+var x = 0;          // 0
+
+for (var i = 0; i < 10; i++) {   // 1 2
+    log(x);
+    if (i) {
+        x = 1;      // 3
+        continue;
+    }
+    x = 2;          // 4
+}
+
+log(x);
@@ -0,0 +1,15 @@
+/*
+    122: Demonstrates a labelled break leaving an outer loop from an inner loop.
+*/
+
+var x = 0;              // 0
+
+outer: while (log) {
+    while (log) {
+        x = 1;          // 1
+        break outer;
+    }
+    x = 2;              // 2
+}
+
+log(x);
@@ -0,0 +1,15 @@
+/*
+    123: Demonstrates a labelled continue skipping the rest of an outer loop body.
+*/
+
+var x = 0;              // 0
+
+outer: for (var i = 0; i < 10; i++) {   // 1 2
+    for (var j = 0; j < 10; j++) {      // 3 4
+        x = 1;          // 5
+        continue outer;
+    }
+    x = 2;              // 6
+}
+
+log(x);
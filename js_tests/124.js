@@ -0,0 +1,28 @@
+/*
+    124: Demonstrates breaking out of a labelled block and a switch case that falls through after a conditional break.
+*/
+
+var x = 0;          // 0
+
+block: {
+    if (log) {
+        x = 1;      // 1
+        break block;
+    }
+    x = 2;          // 2
+}
+
+log(x);
+
+switch (log) {
+    case 1:
+        if (x) {
+            x = 3;  // 3
+            break;
+        }
+        x = 4;      // 4
+    case 2:
+        log(x);
+}
+
+log(x);
@@ -0,0 +1,12 @@
+/*
+    125: Demonstrates a return statement ending the path through an if statement.
+*/
+
+function f() {      // 0
+    var x = 0;      // 1
+    if (log) {
+        x = 1;      // 2
+        return;
+    }
+    log(x);
+}
@@ -0,0 +1,17 @@
+/*
+    126: Demonstrates a throw statement carrying its definitions to the catch block only.
+*/
+
+var x = 0;          // 0
+
+try {
+    if (log) {
+        x = 1;      // 1
+        throw x;
+    }
+    x = 2;          // 2
+} catch (e) {       // 3
+    log(x);
+}
+
+log(x);
@@ -0,0 +1,13 @@
+/*
+    127: Demonstrates a return statement leaving a while loop.
+*/
+
+function f() {          // 0
+    var x = 0;          // 1
+    while (log) {
+        x = 1;          // 2
+        if (x) return;
+        x = 2;          // 3
+    }
+    log(x);
+}
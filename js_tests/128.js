@@ -0,0 +1,13 @@
+/*
+    128: Demonstrates a throw statement stopping a switch case from falling through.
+*/
+
+var x = 0;          // 0
+
+switch (log) {
+    case 1:
+        x = 1;      // 1
+        throw x;
+    case 2:
+        log(x);
+}
@@ -0,0 +1,13 @@
+/*
+    129: Demonstrates a finally block after a try block that always returns.
+*/
+
+function f() {      // 0
+    var x = 0;      // 1
+    try {
+        x = 1;      // 2
+        return;
+    } finally {
+        log(x);
+    }
+}
@@ -0,0 +1,8 @@
+/*
+    130: Demonstrates a declaration with multiple declarators, where later declarators see earlier ones.
+*/
+
+let a = 1, b = a, c;    // 0 1 2
+
+log(b);
+log(c);
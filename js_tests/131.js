@@ -0,0 +1,13 @@
+/*
+    131: Demonstrates redeclaring a var without an initializer keeping its value, while an initializer replaces it.
+*/
+
+var x = 1;      // 0
+var x;          // 1
+
+log(x);
+
+var y = 2;      // 2
+var y = 3;      // 3
+
+log(y);
@@ -0,0 +1,8 @@
+/*
+    132: Demonstrates an initializer running before the variable it initializes is defined.
+*/
+
+var x = 0;          // 0
+var x = x + 1;      // 1
+
+log(x);
@@ -0,0 +1,9 @@
+/*
+    133: Demonstrates a let binding used by its own initializer, inside of its temporal dead zone.
+*/
+
+let a = 0;          // 0
+
+if (log) {
+    let a = a;      // 1
+}
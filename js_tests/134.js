@@ -0,0 +1,11 @@
+/*
+    134: Demonstrates an object pattern defining each bound name from the matching property, with a default as a conditional definition.
+*/
+
+let obj = log;                                  // 0
+const {a, b: [c, d = 5], ...rest} = obj;        // 1, 2, 3, 4, 5
+
+a;
+c;
+d;
+rest;
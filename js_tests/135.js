@@ -0,0 +1,11 @@
+/*
+    135: Demonstrates a destructuring assignment swapping two variables.
+*/
+
+let a = 1;          // 0
+let b = 2;          // 1
+
+[a, b] = [b, a];    // 2, 3
+
+a;
+b;
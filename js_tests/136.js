@@ -0,0 +1,10 @@
+/*
+    136: Demonstrates destructured parameters, including a default value.
+*/
+
+function f({a, b = 1}, [c, ...d]) {     // 0, 1, 2, 3, 4, 5
+    a;
+    b;
+    c;
+    d;
+}
@@ -0,0 +1,14 @@
+/*
+    137: Demonstrates a destructured catch parameter and a destructured for-of binding.
+*/
+
+try {
+    log();
+} catch ({message}) {                   // 0
+    message;
+}
+
+for (const [key, value] of log) {       // 1, 2
+    key;
+    value;
+}
@@ -0,0 +1,11 @@
+/*
+    138: Demonstrates a for-of binding used by the source of its own loop, inside of its temporal dead zone.
+*/
+
+let x = [1];        // 0
+
+for (let x of x) {  // 1
+    log(x);
+}
+
+log(x);
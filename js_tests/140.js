@@ -0,0 +1,16 @@
+/*
+    140: Demonstrates a class declaration in its temporal dead zone, and a method in its own function scope.
+*/
+
+let x = 1;                  // 0
+log(A);
+
+class A {                   // 1
+    m(y) {                  // 2
+        let x = y;          // 3
+        return x;
+    }
+}
+
+log(A);
+log(x);
@@ -0,0 +1,19 @@
+/*
+    141: Demonstrates instance fields and constructor assignments to this as properties seen by methods.
+*/
+
+let v = 1;                  // 0
+
+class A {                   // 1
+    a = v;                  // 3
+    #b;                     // 4
+
+    constructor(c) {        // 2
+        this.c = c;         // 5
+        this.#b = 2;        // 6
+    }
+
+    m() {
+        return this.a + this.#b + this.c + this.d;
+    }
+}
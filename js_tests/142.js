@@ -0,0 +1,20 @@
+/*
+    142: Demonstrates static fields and static blocks running in declaration order when the class is defined.
+*/
+
+let x = 1;                  // 0
+
+class A {                   // 1
+    static a = x;           // 2
+
+    static {
+        log(this.a);
+        x = 3;              // 3
+    }
+
+    static b = x;           // 4
+}
+
+log(x);
+log(A.a);
+log(A.b);
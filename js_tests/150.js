@@ -0,0 +1,14 @@
+/*
+    150: Demonstrates assignments inside short-circuiting and conditional operators as conditional definitions.
+*/
+
+let x = 0;                          // 0
+log && (x = 1);                     // 1
+log(x);
+
+let y = log ? (x = 2) : (x = 3);    // 2, 3, 4
+log(x);
+log(y);
+
+log ?? (x = 4);                     // 5
+log(x);
@@ -0,0 +1,15 @@
+/*
+    151: Demonstrates compound assignments always replacing the previous definitions.
+*/
+
+let x = 0;          // 0
+
+if (log) {
+    x = 1;          // 1
+}
+
+x += 2;             // 2
+log(x);
+
+x || (x *= 3);      // 3
+log(x);
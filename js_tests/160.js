@@ -0,0 +1,28 @@
+/*
+    160: Demonstrates identifiers that aren't references, and the roles of the ones that are.
+*/
+
+let f = function () {       // 0
+    return new.target;
+};
+let obj = {f: f};           // 1, 2
+let n = 0;                  // 3
+
+outer: for (;;) {
+    n++;                    // 4
+    break outer;
+}
+
+f(n);
+new f();
+obj.f();
+obj.f += n;                 // 5
+
+class A {                   // 6
+    #x = 1;                 // 7
+    static has(o) {         // 8
+        return #x in o;
+    }
+}
+
+A.has(obj);
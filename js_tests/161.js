@@ -0,0 +1,17 @@
+/*
+    161: Demonstrates globals provided by the environment, globals redefined by the program, and unresolved globals.
+*/
+
+let n = Math.max(1, 2);     // 1
+
+if (n) {
+    JSON = null;            // 2
+}
+
+log(JSON);
+log(Object.keys);
+log(undeclared);
+
+function f(Map) {           // 0, 3
+    return Map;
+}
@@ -0,0 +1,11 @@
+/*
+    162: Demonstrates a function using a variable nothing declares, which isn't captured from outside of the function.
+*/
+
+let x = 1;                  // 0
+
+let f = function () {       // 1
+    log(x, y);
+};
+
+f();
@@ -0,0 +1,14 @@
+/*
+    163: Demonstrates reading an object as a whole, which uses the definitions of every property it holds.
+*/
+
+let o = {};                 // 0
+o.a = 1;                    // 1
+o.b = {};                   // 2
+o.b.c = 2;                  // 3
+
+let p = { d: 3 };           // 4, p.d 5
+p.d = 4;                    // 6
+
+log(o);
+log(p.e);
@@ -0,0 +1,13 @@
+/*
+    01: Demonstrates if statements and short-circuiting expressions branching.
+*/
+
+let x = a && b;
+
+if (x) {
+    x = c ? 1 : 2;
+} else if (y) {
+    x = x ?? 3;
+}
+
+log(x);
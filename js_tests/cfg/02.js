@@ -0,0 +1,18 @@
+/*
+    02: Demonstrates loops with labelled break and continue statements.
+*/
+
+outer: for (let i = 0; i < 3; i++) {
+    for (const k in obj) {
+        if (k) continue outer;
+        break;
+    }
+
+    do {
+        i--;
+    } while (i > 5);
+}
+
+while (x) {
+    x--;
+}
@@ -0,0 +1,15 @@
+/*
+    03: Demonstrates switch statements falling through, and breaking out of labelled blocks.
+*/
+
+block: {
+    switch (x) {
+        case 1:
+            a();
+        case 2:
+            b();
+            break block;
+        default:
+            c();
+    }
+}
@@ -0,0 +1,14 @@
+/*
+    04: Demonstrates exceptions, and jumps running finally blocks.
+*/
+
+function f(x) {
+    try {
+        if (x) return 1;
+        g();
+    } catch (e) {
+        throw e;
+    } finally {
+        h();
+    }
+}
@@ -0,0 +1,22 @@
+/*
+    05: Demonstrates graphs of their own for functions, arrow functions, constructors and methods.
+*/
+
+const add = (a, b = 1) => a + b;
+
+class A extends B {
+    static count = 0;
+    x = 1;
+
+    constructor() {
+        super();
+    }
+
+    m() {
+        return o?.p;
+    }
+
+    static {
+        A.count++;
+    }
+}
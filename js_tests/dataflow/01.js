@@ -0,0 +1,20 @@
+let a = 1;
+let b = a + 2;
+let unused = 5;
+unused = 6;
+
+if (cond) {
+    a = 10;
+} else {
+    a = 1;
+}
+
+let c = b * 2;
+log(a, c);
+
+let i = 0;
+while (i < 10) {
+    i = i + 1;
+}
+
+log(i, 0 / 0);
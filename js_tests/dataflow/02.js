@@ -0,0 +1,25 @@
+let count = 0;
+let name = "x";
+
+function bump() {
+    count = count + 1;
+    return name;
+}
+
+let greeting = "hello " + name;
+bump();
+
+try {
+    name = "y";
+    risky();
+    name = "z";
+} catch (e) {
+    log(e, name);
+}
+
+for (const item of items) {
+    let doubled = item * 2;
+    log(doubled, greeting);
+}
+
+log(count);
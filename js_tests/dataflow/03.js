@@ -0,0 +1,21 @@
+let input = read();
+let clean = sanitize(input);
+let alias = clean;
+
+if (strict) {
+    input = sanitize(input);
+}
+
+sink(input, clean, alias);
+
+clean = read();
+sink(clean);
+
+function handler(req) {
+    let body = sanitize(req.body);
+    if (req.raw) {
+        body = req.body;
+    }
+
+    sink(body);
+}
@@ -0,0 +1,17 @@
+let x = 1;
+let y = 2;
+
+if (cond) {
+    x = x + y;
+} else {
+    y = 3;
+}
+
+log(x, y);
+
+let i = 0;
+while (i < x) {
+    i++;
+}
+
+log(i);
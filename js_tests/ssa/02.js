@@ -0,0 +1,25 @@
+function f(a, b = 1) {
+    let r = a;
+    try {
+        r = risky(b);
+        r = r * 2;
+    } catch (e) {
+        log(e, r);
+    } finally {
+        b = r;
+    }
+
+    return b;
+}
+
+for (const k of keys) {
+    let v = k;
+    if (v) {
+        continue;
+    }
+    log(v);
+}
+
+let s = 0;
+s += f(s);
+log(s);
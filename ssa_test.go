@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/civiledcode/javascribe/dfa"
+	"github.com/t14raptor/go-fast/parser"
+)
+
+var ssaTestsRan = []string{"01", "02"}
+
+func TestSSA(t *testing.T) {
+	for _, testName := range ssaTestsRan {
+		jsCode, err := os.ReadFile("./js_tests/ssa/" + testName + ".js")
+		if err != nil {
+			panic(err)
+		}
+
+		a, err := parser.ParseFile(string(jsCode))
+		if err != nil {
+			panic(err)
+		}
+
+		s := dfa.BuildSSA(dfa.BuildCFG(a))
+		got := s.String()
+		if os.Getenv("SSA_WRITE") != "" {
+			os.WriteFile("./js_tests/ssa/"+testName+".txt", []byte(got), 0644)
+			continue
+		}
+
+		expected, err := os.ReadFile("./js_tests/ssa/" + testName + ".txt")
+		if err != nil {
+			panic(err)
+		}
+
+		if got != string(expected) {
+			t.Fatalf("incorrect SSA form from test ssa/%s.js:\nexpected:\n%s\ngot:\n%s", testName, expected, got)
+		}
+
+		t.Logf("Test ssa/%s PASSED!\n\n", testName)
+	}
+}
+
+// TestSSAValues checks that every variable an item accesses in the fixtures maps to exactly one value,
+// and that no value is defined twice.
+func TestSSAValues(t *testing.T) {
+	for _, testName := range testsRan {
+		jsCode, err := os.ReadFile("./js_tests/" + testName + ".js")
+		if err != nil {
+			panic(err)
+		}
+
+		a, err := parser.ParseFile(string(jsCode))
+		if err != nil {
+			panic(err)
+		}
+
+		c := dfa.BuildCFG(a)
+		s := dfa.BuildSSA(c)
+
+		defined := make(map[string]bool)
+		for _, g := range c.Functions {
+			for _, n := range g.Nodes {
+				for _, phi := range s.Phis[n] {
+					if defined[phi.Value.String()] {
+						t.Fatalf("test %s defines %s twice", testName, phi.Value)
+					}
+					defined[phi.Value.String()] = true
+				}
+
+				for _, item := range n.Items {
+					for _, acc := range c.Accesses(item) {
+						if acc.Deferred || !acc.Direct() {
+							continue
+						}
+
+						if !acc.Write {
+							if s.Use(item, acc.Id) == nil {
+								t.Fatalf("test %s has no value for the use of %s", testName, acc.Id.Name)
+							}
+							continue
+						}
+
+						v := s.Def(item, acc.Id)
+						if v == nil {
+							t.Fatalf("test %s has no value for the definition of %s", testName, acc.Id.Name)
+						}
+
+						if defined[v.String()] {
+							t.Fatalf("test %s defines %s twice", testName, v)
+						}
+						defined[v.String()] = true
+					}
+				}
+			}
+
+			// Values are only unique within their graph.
+			clear(defined)
+		}
+	}
+}